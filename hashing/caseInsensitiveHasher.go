@@ -0,0 +1,63 @@
+package hashing
+
+import "github.com/dmarro89/go-redis-hashtable/utility"
+
+// CaseInsensitiveHasher wraps another hasher so that keys differing only in the case
+// of their ASCII letters have the same digest, like the Redis dictGenCaseHashFunction.
+type CaseInsensitiveHasher struct {
+	Hasher IHasher[string]
+}
+
+// NewCaseInsensitiveHasher returns a hasher folding the case of the keys before hashing them with hasher.
+func NewCaseInsensitiveHasher(hasher IHasher[string]) *CaseInsensitiveHasher {
+	return &CaseInsensitiveHasher{Hasher: hasher}
+}
+
+func (h *CaseInsensitiveHasher) Digest(message string) uint64 {
+	return h.Hasher.Digest(toLowerASCII(message))
+}
+
+// DigestBytes returns the digest of message, equal to the digest of the same bytes as a string.
+func (h *CaseInsensitiveHasher) DigestBytes(message []byte) uint64 {
+	return h.Digest(utility.UnsafeString(message))
+}
+
+// Equal reports whether a and b are equal under ASCII case folding.
+// It is the key comparison consistent with Digest.
+func (h *CaseInsensitiveHasher) Equal(a, b string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := 0; i < len(a); i++ {
+		if lowerASCII(a[i]) != lowerASCII(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// toLowerASCII returns s with its ASCII letters mapped to lower case.
+// s is returned as is, without allocating, when it has no upper case letters.
+func toLowerASCII(s string) string {
+	i := 0
+	for i < len(s) && (s[i] < 'A' || s[i] > 'Z') {
+		i++
+	}
+	if i == len(s) {
+		return s
+	}
+
+	b := []byte(s)
+	for ; i < len(b); i++ {
+		b[i] = lowerASCII(b[i])
+	}
+	return string(b)
+}
+
+// lowerASCII maps an ASCII upper case letter to lower case.
+func lowerASCII(c byte) byte {
+	if c >= 'A' && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
@@ -0,0 +1,32 @@
+package hashing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaseInsensitiveDigest(t *testing.T) {
+	inner := NewSip24Hasher()
+	hasher := NewCaseInsensitiveHasher(inner)
+
+	assert.Equal(t, hasher.Digest("foo"), hasher.Digest("FOO"), "Digest should ignore the case of the key")
+	assert.Equal(t, hasher.Digest("foo"), hasher.Digest("fOo"), "Digest should ignore the case of the key")
+	assert.Equal(t, inner.Digest("foo"), hasher.Digest("FoO"), "Digest should hash the lower case key")
+	assert.NotEqual(t, hasher.Digest("foo"), hasher.Digest("bar"), "Different keys should have different digests")
+}
+
+func TestCaseInsensitiveEqual(t *testing.T) {
+	hasher := NewCaseInsensitiveHasher(NewSip24Hasher())
+
+	assert.True(t, hasher.Equal("config", "CONFIG"), "Keys differing in case should be equal")
+	assert.True(t, hasher.Equal("", ""), "Empty keys should be equal")
+	assert.False(t, hasher.Equal("config", "conf"), "Keys with different lengths should not be equal")
+	assert.False(t, hasher.Equal("a-b", "a_b"), "Non letters should be compared exactly")
+}
+
+func TestToLowerASCII(t *testing.T) {
+	assert.Equal(t, "already lower", toLowerASCII("already lower"))
+	assert.Equal(t, "mixed case 123", toLowerASCII("MiXeD CASE 123"))
+	assert.Equal(t, "ünicode", toLowerASCII("ünicode"), "Only ASCII letters should be folded")
+}
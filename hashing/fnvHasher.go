@@ -0,0 +1,33 @@
+package hashing
+
+import "github.com/dmarro89/go-redis-hashtable/utility"
+
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+// Fnv1aHasher hashes keys with the 64-bit FNV-1a function.
+// It is not keyed, so it should only be used with trusted keys.
+type Fnv1aHasher struct{}
+
+var globalFnv1aHasher = &Fnv1aHasher{}
+
+// NewFnv1aHasher returns an FNV-1a hasher.
+func NewFnv1aHasher() IHasher[string] {
+	return globalFnv1aHasher
+}
+
+func (h *Fnv1aHasher) Digest(message string) uint64 {
+	hash := uint64(fnvOffset64)
+	for i := 0; i < len(message); i++ {
+		hash ^= uint64(message[i])
+		hash *= fnvPrime64
+	}
+	return hash
+}
+
+// DigestBytes returns the digest of message, equal to the digest of the same bytes as a string.
+func (h *Fnv1aHasher) DigestBytes(message []byte) uint64 {
+	return h.Digest(utility.UnsafeString(message))
+}
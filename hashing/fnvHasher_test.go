@@ -0,0 +1,18 @@
+package hashing
+
+import (
+	"hash/fnv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFnv1aDigest(t *testing.T) {
+	hasher := NewFnv1aHasher()
+
+	for _, message := range []string{"", "a", "test message", "a much longer message to hash with FNV-1a"} {
+		reference := fnv.New64a()
+		reference.Write([]byte(message))
+		assert.Equal(t, reference.Sum64(), hasher.Digest(message), "Digest(%q) should return the FNV-1a hash", message)
+	}
+}
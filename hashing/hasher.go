@@ -7,8 +7,32 @@ import (
 	"github.com/dmarro89/go-redis-hashtable/utility"
 )
 
-type IHasher interface {
-	Digest(message string) uint64
+// IHasher computes the 64-bit digest used to place a key of type K in a bucket.
+type IHasher[K comparable] interface {
+	Digest(key K) uint64
+}
+
+// IBytesHasher is implemented by the string hashers that can also hash a byte slice
+// directly, without converting it to a string first. DigestBytes(b) is equal to Digest(string(b)).
+type IBytesHasher interface {
+	IHasher[string]
+	DigestBytes(message []byte) uint64
+}
+
+// HasherFunc adapts an ordinary function to the IHasher interface, so that any
+// key type can be hashed without declaring a dedicated hasher type.
+type HasherFunc[K comparable] func(key K) uint64
+
+// Digest calls f(key).
+func (f HasherFunc[K]) Digest(key K) uint64 {
+	return f(key)
+}
+
+// ISeededHasher is implemented by the hashers keyed with a 16-byte seed.
+// The seed determines the bucket layout of a dictionary, so reading it back allows
+// to build another hasher with the same layout.
+type ISeededHasher interface {
+	Seed() [16]byte
 }
 
 type Sip24Hasher struct {
@@ -16,15 +40,29 @@ type Sip24Hasher struct {
 	Key1 uint64
 }
 
-var key0, key1 = Split(utility.GetRandomBytes())
-var globalSip24Hasher = &Sip24Hasher{Key0: key0, Key1: key1}
+// NewSip24Hasher returns a SipHash-2-4 hasher keyed with a newly generated random seed.
+func NewSip24Hasher() IHasher[string] {
+	return NewSip24HasherWithSeed(utility.NewRandomBytes())
+}
 
-func NewSip24Hasher() IHasher {
-	return globalSip24Hasher
+// NewSip24HasherWithSeed returns a SipHash-2-4 hasher keyed with the given seed.
+func NewSip24HasherWithSeed(seed [16]byte) IHasher[string] {
+	key0, key1 := Split(seed)
+	return &Sip24Hasher{Key0: key0, Key1: key1}
 }
 
 func (h *Sip24Hasher) Digest(message string) uint64 {
-	return siphash.Hash(h.Key0, h.Key1, []byte(message))
+	return siphash.Hash(h.Key0, h.Key1, utility.UnsafeBytes(message))
+}
+
+// DigestBytes returns the digest of message, equal to the digest of the same bytes as a string.
+func (h *Sip24Hasher) DigestBytes(message []byte) uint64 {
+	return siphash.Hash(h.Key0, h.Key1, message)
+}
+
+// Seed returns the 16-byte seed the hasher is keyed with.
+func (h *Sip24Hasher) Seed() [16]byte {
+	return Join(h.Key0, h.Key1)
 }
 
 func Split(key [16]byte) (uint64, uint64) {
@@ -32,3 +70,11 @@ func Split(key [16]byte) (uint64, uint64) {
 	key1 := binary.LittleEndian.Uint64(key[8:])
 	return key0, key1
 }
+
+// Join is the inverse of Split: it returns the 16-byte key made of key0 and key1.
+func Join(key0 uint64, key1 uint64) [16]byte {
+	var key [16]byte
+	binary.LittleEndian.PutUint64(key[:8], key0)
+	binary.LittleEndian.PutUint64(key[8:], key1)
+	return key
+}
@@ -23,3 +23,62 @@ func TestDigest(t *testing.T) {
 	actualHash := hasher.Digest(message)
 	assert.Equal(t, expectedHash, actualHash, "Digest(%q) should return correct hash", message)
 }
+
+func TestHasherFunc(t *testing.T) {
+	var hasher IHasher[int] = HasherFunc[int](func(key int) uint64 {
+		return uint64(key) * 31
+	})
+
+	assert.Equal(t, uint64(0), hasher.Digest(0), "HasherFunc should delegate to the wrapped function")
+	assert.Equal(t, uint64(62), hasher.Digest(2), "HasherFunc should delegate to the wrapped function")
+}
+
+func TestNewSip24HasherWithSeed(t *testing.T) {
+	seed := [16]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+	hasher := NewSip24HasherWithSeed(seed).(*Sip24Hasher)
+
+	key0, key1 := Split(seed)
+	assert.Equal(t, key0, hasher.Key0, "NewSip24HasherWithSeed() should set key0 from the seed")
+	assert.Equal(t, key1, hasher.Key1, "NewSip24HasherWithSeed() should set key1 from the seed")
+	assert.Equal(t, seed, hasher.Seed(), "Seed() should return the seed the hasher was built with")
+
+	other := NewSip24HasherWithSeed(seed)
+	assert.Equal(t, hasher.Digest("test message"), other.Digest("test message"), "Hashers with the same seed should return the same digest")
+}
+
+func TestNewSip24HasherSeedPerInstance(t *testing.T) {
+	first := NewSip24Hasher().(*Sip24Hasher)
+	second := NewSip24Hasher().(*Sip24Hasher)
+
+	assert.NotEqual(t, first.Seed(), second.Seed(), "Each hasher should have its own random seed")
+}
+
+func TestJoin(t *testing.T) {
+	seed := [16]byte{15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1, 0}
+	assert.Equal(t, seed, Join(Split(seed)), "Join should be the inverse of Split")
+}
+
+func TestDigestBytes(t *testing.T) {
+	hashers := map[string]IBytesHasher{
+		"sip24":           NewSip24Hasher().(IBytesHasher),
+		"sip13":           NewSip13Hasher().(IBytesHasher),
+		"fnv1a":           NewFnv1aHasher().(IBytesHasher),
+		"xxhash":          NewXxHasher().(IBytesHasher),
+		"maphash":         NewMapHasher().(IBytesHasher),
+		"caseInsensitive": NewCaseInsensitiveHasher(NewSip24Hasher()),
+	}
+
+	for name, hasher := range hashers {
+		for _, message := range []string{"", "a", "test message", "a message longer than thirty-two bytes"} {
+			assert.Equal(t, hasher.Digest(message), hasher.DigestBytes([]byte(message)), "%s: DigestBytes(%q) should equal Digest", name, message)
+		}
+	}
+}
+
+func TestSip24DigestDoesNotAllocate(t *testing.T) {
+	hasher := NewSip24Hasher()
+	message := "a message longer than thirty-two bytes"
+
+	allocs := testing.AllocsPerRun(100, func() { hasher.Digest(message) })
+	assert.Zero(t, allocs, "Digest should not convert the message to a byte slice")
+}
@@ -0,0 +1,22 @@
+package hashing
+
+import "hash/maphash"
+
+// MapHasher hashes keys with hash/maphash, the hash function of the Go runtime maps.
+type MapHasher struct {
+	Seed maphash.Seed
+}
+
+// NewMapHasher returns a maphash hasher with a newly generated random seed.
+func NewMapHasher() IHasher[string] {
+	return &MapHasher{Seed: maphash.MakeSeed()}
+}
+
+func (h *MapHasher) Digest(message string) uint64 {
+	return maphash.String(h.Seed, message)
+}
+
+// DigestBytes returns the digest of message, equal to the digest of the same bytes as a string.
+func (h *MapHasher) DigestBytes(message []byte) uint64 {
+	return maphash.Bytes(h.Seed, message)
+}
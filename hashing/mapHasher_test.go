@@ -0,0 +1,16 @@
+package hashing
+
+import (
+	"hash/maphash"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapDigest(t *testing.T) {
+	hasher := NewMapHasher().(*MapHasher)
+	message := "test message"
+
+	assert.Equal(t, maphash.String(hasher.Seed, message), hasher.Digest(message), "Digest(%q) should return the maphash hash", message)
+	assert.Equal(t, hasher.Digest(message), hasher.Digest(message), "Digest(%q) should be stable", message)
+}
@@ -0,0 +1,68 @@
+package hashing
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/dchest/siphash"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSip13Hasher(t *testing.T) {
+	hasher := NewSip13Hasher().(*Sip13Hasher)
+	assert.NotNil(t, hasher, "NewSip13Hasher() should not return nil")
+
+	assert.NotEqual(t, uint64(0), hasher.Key0, "NewSip13Hasher() should set key0")
+	assert.NotEqual(t, uint64(0), hasher.Key1, "NewSip13Hasher() should set key1")
+}
+
+func TestSipHashRounds(t *testing.T) {
+	// With 2 compression and 4 finalization rounds sipHash must match the reference SipHash-2-4
+	hasher := NewSip24Hasher().(*Sip24Hasher)
+	for length := 0; length < 64; length++ {
+		message := fmt.Sprintf("%064d", length)[:length]
+		expected := siphash.Hash(hasher.Key0, hasher.Key1, []byte(message))
+		assert.Equal(t, expected, sipHash(2, 4, hasher.Key0, hasher.Key1, message), "Unexpected digest for a message of length %d", length)
+	}
+}
+
+func TestSip13Digest(t *testing.T) {
+	hasher := &Sip13Hasher{Key0: 1, Key1: 2}
+	message := "test message"
+
+	assert.Equal(t, sipHash(1, 3, 1, 2, message), hasher.Digest(message), "Digest(%q) should return the SipHash-1-3 hash", message)
+	assert.NotEqual(t, sipHash(2, 4, 1, 2, message), hasher.Digest(message), "SipHash-1-3 should differ from SipHash-2-4")
+}
+
+func TestSip13DigestWithKnownVectors(t *testing.T) {
+	// Reference SipHash-1-3 vectors, with the key 00..0f and the message 00..n-1 for the n-th vector
+	expected := []uint64{
+		0xabac0158050fc4dc, 0xc9f49bf37d57ca93, 0x82cb9b024dc7d44d, 0x8bf80ab8e7ddf7fb,
+		0xcf75576088d38328, 0xdef9d52f49533b67, 0xc50d2b50c59f22a7, 0xd3927d989bb11140,
+		0x369095118d299a8e, 0x25a48eb36c063de4, 0x79de85ee92ff097f, 0x70c118c1f94dc352,
+		0x78a384b157b4d9a2, 0x306f760c1229ffa7, 0x605aa111c0f95d34, 0xd320d86d2a519956,
+	}
+
+	var key [16]byte
+	message := make([]byte, len(expected))
+	for i := range key {
+		key[i] = byte(i)
+	}
+	for i := range message {
+		message[i] = byte(i)
+	}
+	key0, key1 := Split(key)
+	hasher := &Sip13Hasher{Key0: key0, Key1: key1}
+
+	for length, digest := range expected {
+		assert.Equal(t, digest, hasher.Digest(string(message[:length])), "Unexpected digest for a message of length %d", length)
+	}
+}
+
+func TestNewSip13HasherWithSeed(t *testing.T) {
+	seed := [16]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+	hasher := NewSip13HasherWithSeed(seed).(*Sip13Hasher)
+
+	assert.Equal(t, seed, hasher.Seed(), "Seed() should return the seed the hasher was built with")
+	assert.Equal(t, hasher.Digest("test message"), NewSip13HasherWithSeed(seed).Digest("test message"), "Hashers with the same seed should return the same digest")
+}
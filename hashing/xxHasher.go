@@ -0,0 +1,105 @@
+package hashing
+
+import (
+	"math/bits"
+
+	"github.com/dmarro89/go-redis-hashtable/utility"
+)
+
+const (
+	xxPrime1 uint64 = 11400714785074694791
+	xxPrime2 uint64 = 14029467366897019727
+	xxPrime3 uint64 = 1609587929392839161
+	xxPrime4 uint64 = 9650029242287828579
+	xxPrime5 uint64 = 2870177450012600261
+)
+
+// XxHasher hashes keys with the 64-bit xxHash function (XXH64).
+// The seed only changes the bucket layout, it does not protect against hash flooding.
+type XxHasher struct {
+	Seed uint64
+}
+
+// NewXxHasher returns an xxHash64 hasher with a newly generated random seed.
+func NewXxHasher() IHasher[string] {
+	seed, _ := Split(utility.NewRandomBytes())
+	return &XxHasher{Seed: seed}
+}
+
+func (h *XxHasher) Digest(message string) uint64 {
+	length := uint64(len(message))
+	var hash uint64
+
+	if len(message) >= 32 {
+		v1 := h.Seed + xxPrime1 + xxPrime2
+		v2 := h.Seed + xxPrime2
+		v3 := h.Seed
+		v4 := h.Seed - xxPrime1
+		for ; len(message) >= 32; message = message[32:] {
+			v1 = xxRound(v1, readUint64(message[0:8]))
+			v2 = xxRound(v2, readUint64(message[8:16]))
+			v3 = xxRound(v3, readUint64(message[16:24]))
+			v4 = xxRound(v4, readUint64(message[24:32]))
+		}
+
+		hash = bits.RotateLeft64(v1, 1) + bits.RotateLeft64(v2, 7) + bits.RotateLeft64(v3, 12) + bits.RotateLeft64(v4, 18)
+		hash = xxMergeRound(hash, v1)
+		hash = xxMergeRound(hash, v2)
+		hash = xxMergeRound(hash, v3)
+		hash = xxMergeRound(hash, v4)
+	} else {
+		hash = h.Seed + xxPrime5
+	}
+
+	hash += length
+
+	for ; len(message) >= 8; message = message[8:] {
+		hash ^= xxRound(0, readUint64(message[:8]))
+		hash = bits.RotateLeft64(hash, 27)*xxPrime1 + xxPrime4
+	}
+	if len(message) >= 4 {
+		hash ^= readUint32(message[:4]) * xxPrime1
+		hash = bits.RotateLeft64(hash, 23)*xxPrime2 + xxPrime3
+		message = message[4:]
+	}
+	for i := 0; i < len(message); i++ {
+		hash ^= uint64(message[i]) * xxPrime5
+		hash = bits.RotateLeft64(hash, 11) * xxPrime1
+	}
+
+	hash ^= hash >> 33
+	hash *= xxPrime2
+	hash ^= hash >> 29
+	hash *= xxPrime3
+	hash ^= hash >> 32
+	return hash
+}
+
+// DigestBytes returns the digest of message, equal to the digest of the same bytes as a string.
+func (h *XxHasher) DigestBytes(message []byte) uint64 {
+	return h.Digest(utility.UnsafeString(message))
+}
+
+// xxRound mixes an 8-byte lane of input into an accumulator.
+func xxRound(acc, input uint64) uint64 {
+	acc += input * xxPrime2
+	acc = bits.RotateLeft64(acc, 31)
+	return acc * xxPrime1
+}
+
+// xxMergeRound merges an accumulator into the final hash.
+func xxMergeRound(hash, acc uint64) uint64 {
+	hash ^= xxRound(0, acc)
+	return hash*xxPrime1 + xxPrime4
+}
+
+// readUint64 reads 8 bytes of s as a little-endian uint64 without allocating.
+func readUint64(s string) uint64 {
+	return uint64(s[0]) | uint64(s[1])<<8 | uint64(s[2])<<16 | uint64(s[3])<<24 |
+		uint64(s[4])<<32 | uint64(s[5])<<40 | uint64(s[6])<<48 | uint64(s[7])<<56
+}
+
+// readUint32 reads 4 bytes of s as a little-endian uint32, widened to uint64.
+func readUint32(s string) uint64 {
+	return uint64(s[0]) | uint64(s[1])<<8 | uint64(s[2])<<16 | uint64(s[3])<<24
+}
@@ -0,0 +1,29 @@
+package hashing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXxDigestWithKnownVectors(t *testing.T) {
+	tests := []struct {
+		message string
+		expect  uint64
+	}{
+		{message: "", expect: 0xef46db3751d8e999},
+		{message: "a", expect: 0xd24ec4f1a98c6e5b},
+		{message: "abc", expect: 0x44bc2cf5ad770999},
+		{message: "Nobody inspects the spammish repetition", expect: 0xfbcea83c8a378bf1},
+	}
+
+	hasher := &XxHasher{Seed: 0}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expect, hasher.Digest(tt.message), "Digest(%q) should return the XXH64 hash", tt.message)
+	}
+}
+
+func TestXxDigestSeed(t *testing.T) {
+	message := "test message"
+	assert.NotEqual(t, (&XxHasher{Seed: 0}).Digest(message), (&XxHasher{Seed: 1}).Digest(message), "Different seeds should produce different hashes")
+}
@@ -0,0 +1,281 @@
+package keyspace
+
+import (
+	"time"
+
+	"github.com/dmarro89/go-redis-hashtable/hashing"
+	"github.com/dmarro89/go-redis-hashtable/structure"
+)
+
+const (
+	// ACTIVE_EXPIRE_KEYS_PER_LOOP is the number of keys with an expire sampled by each loop of the active expire cycle.
+	ACTIVE_EXPIRE_KEYS_PER_LOOP = 20
+	// ACTIVE_EXPIRE_ACCEPTABLE_STALE is the percentage of expired keys among the sampled ones
+	// under which the active expire cycle stops.
+	ACTIVE_EXPIRE_ACCEPTABLE_STALE = 10
+)
+
+// object wraps the values stored in the keyspace, so that a missing key (nil)
+// can be told apart from a key holding an empty string.
+type object struct {
+	value string
+}
+
+// Keyspace is a key/value store with expiration, built like a Redis database:
+// a main dictionary holds the values and a second "expires" dictionary holds the
+// absolute unix time in milliseconds at which each volatile key expires.
+//
+// Expired keys are removed lazily when they are accessed, and actively by ActiveExpireCycle.
+type Keyspace struct {
+	dict          structure.IDict[string, *object]
+	expires       structure.IDict[string, int64]
+	expiresCursor uint64
+	now           func() time.Time
+}
+
+// NewKeyspace creates a new empty Keyspace.
+//
+// The function does not take any parameters.
+// It returns a pointer to Keyspace.
+func NewKeyspace() *Keyspace {
+	return &Keyspace{
+		dict:    structure.NewDict[string, *object](hashing.NewSip24Hasher()),
+		expires: structure.NewDict[string, int64](hashing.NewSip24Hasher()),
+		now:     time.Now,
+	}
+}
+
+// nowMilliseconds returns the current unix time in milliseconds.
+func (k *Keyspace) nowMilliseconds() int64 {
+	return k.now().UnixMilli()
+}
+
+// expireIfNeeded deletes the key if it has an expire in the past.
+//
+// Parameters:
+// - key: the key to check.
+//
+// Returns:
+// - bool: true if the key was expired and deleted, false otherwise.
+func (k *Keyspace) expireIfNeeded(key string) bool {
+	when := k.expires.Get(key)
+	if when == 0 || when > k.nowMilliseconds() {
+		return false
+	}
+
+	k.dict.Delete(key)
+	k.expires.Delete(key)
+	return true
+}
+
+// lookup returns the object stored at key, expiring the key first if needed.
+//
+// Parameters:
+// - key: the key to look up.
+//
+// Returns:
+// - *object: the object stored at key, or nil if the key does not exist.
+func (k *Keyspace) lookup(key string) *object {
+	if k.expireIfNeeded(key) {
+		return nil
+	}
+	return k.dict.Get(key)
+}
+
+// Set sets the value of a key, removing any expire previously associated with it.
+//
+// Parameters:
+// - key: the key to set the value for.
+// - value: the value to set.
+//
+// Returns:
+// - error: an error if the value could not be stored.
+func (k *Keyspace) Set(key string, value string) error {
+	if err := k.dict.Set(key, &object{value: value}); err != nil {
+		return err
+	}
+	k.expires.Delete(key)
+	return nil
+}
+
+// Get returns the value of a key, deleting it first if it is expired.
+//
+// Parameters:
+// - key: the key to look up.
+//
+// Returns:
+// - string: the value of the key, or an empty string if the key does not exist.
+func (k *Keyspace) Get(key string) string {
+	obj := k.lookup(key)
+	if obj == nil {
+		return ""
+	}
+	return obj.value
+}
+
+// Lookup returns the value of a key and whether it exists, deleting it first if it is expired.
+//
+// Parameters:
+// - key: the key to look up.
+//
+// Returns:
+// - string: the value of the key, or an empty string if the key does not exist.
+// - bool: true if the key exists, false otherwise.
+func (k *Keyspace) Lookup(key string) (string, bool) {
+	obj := k.lookup(key)
+	if obj == nil {
+		return "", false
+	}
+	return obj.value, true
+}
+
+// Delete deletes a key and its expire.
+//
+// Parameters:
+// - key: the key to delete.
+//
+// Returns:
+// - error: structure.ErrKeyNotFound if the key does not exist.
+func (k *Keyspace) Delete(key string) error {
+	if k.lookup(key) == nil {
+		return structure.ErrKeyNotFound
+	}
+	k.expires.Delete(key)
+	return k.dict.Delete(key)
+}
+
+// Expire sets a timeout of the given seconds on a key, like the Redis EXPIRE command.
+// A non positive timeout deletes the key immediately.
+//
+// Parameters:
+// - key: the key to set the timeout on.
+// - seconds: the timeout in seconds.
+//
+// Returns:
+// - bool: true if the timeout was set, false if the key does not exist.
+func (k *Keyspace) Expire(key string, seconds int64) bool {
+	return k.PExpireAt(key, k.nowMilliseconds()+seconds*1000)
+}
+
+// PExpireAt sets the absolute unix time in milliseconds at which a key expires,
+// like the Redis PEXPIREAT command. A time in the past deletes the key immediately.
+//
+// Parameters:
+// - key: the key to set the expire on.
+// - milliseconds: the unix time in milliseconds at which the key expires.
+//
+// Returns:
+// - bool: true if the expire was set, false if the key does not exist.
+func (k *Keyspace) PExpireAt(key string, milliseconds int64) bool {
+	if k.lookup(key) == nil {
+		return false
+	}
+
+	if milliseconds <= k.nowMilliseconds() {
+		k.dict.Delete(key)
+		k.expires.Delete(key)
+		return true
+	}
+
+	k.expires.Set(key, milliseconds)
+	return true
+}
+
+// PTTL returns the remaining time to live of a key in milliseconds, like the Redis PTTL command.
+//
+// Parameters:
+// - key: the key to inspect.
+//
+// Returns:
+// - int64: the remaining time to live, -2 if the key does not exist, -1 if the key has no expire.
+func (k *Keyspace) PTTL(key string) int64 {
+	if k.lookup(key) == nil {
+		return -2
+	}
+
+	when := k.expires.Get(key)
+	if when == 0 {
+		return -1
+	}
+	return when - k.nowMilliseconds()
+}
+
+// TTL returns the remaining time to live of a key in seconds, like the Redis TTL command.
+//
+// Parameters:
+// - key: the key to inspect.
+//
+// Returns:
+// - int64: the remaining time to live, -2 if the key does not exist, -1 if the key has no expire.
+func (k *Keyspace) TTL(key string) int64 {
+	ttl := k.PTTL(key)
+	if ttl < 0 {
+		return ttl
+	}
+	return (ttl + 500) / 1000
+}
+
+// Persist removes the expire of a key, like the Redis PERSIST command.
+//
+// Parameters:
+// - key: the key to make persistent.
+//
+// Returns:
+// - bool: true if the expire was removed, false if the key does not exist or has no expire.
+func (k *Keyspace) Persist(key string) bool {
+	if k.lookup(key) == nil {
+		return false
+	}
+	return k.expires.Delete(key) == nil
+}
+
+// ActiveExpireCycle samples keys with an expire and deletes the expired ones, like the Redis
+// activeExpireCycle. The expires dictionary is walked with a Scan cursor kept across calls,
+// ACTIVE_EXPIRE_KEYS_PER_LOOP keys per loop, and the cycle stops as soon as less than
+// ACTIVE_EXPIRE_ACCEPTABLE_STALE percent of the sampled keys were expired, or the time
+// budget is exhausted.
+//
+// Parameters:
+// - budget: the maximum time spent by the cycle.
+//
+// Returns:
+// - int: the number of expired keys deleted.
+func (k *Keyspace) ActiveExpireCycle(budget time.Duration) int {
+	start := time.Now()
+	deleted := 0
+
+	for {
+		now := k.nowMilliseconds()
+		sampled := 0
+		var expired []string
+
+		for sampled < ACTIVE_EXPIRE_KEYS_PER_LOOP {
+			k.expiresCursor = k.expires.Scan(k.expiresCursor, func(key string, when int64) {
+				sampled++
+				if when <= now {
+					expired = append(expired, key)
+				}
+			})
+			if k.expiresCursor == 0 {
+				break
+			}
+		}
+
+		// Scan may return a key twice, only count the keys actually deleted
+		for _, key := range expired {
+			if k.expires.Delete(key) == nil {
+				k.dict.Delete(key)
+				deleted++
+			}
+		}
+
+		if sampled == 0 || len(expired)*100/sampled <= ACTIVE_EXPIRE_ACCEPTABLE_STALE {
+			break
+		}
+		if time.Since(start) > budget {
+			break
+		}
+	}
+
+	return deleted
+}
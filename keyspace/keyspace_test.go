@@ -0,0 +1,134 @@
+package keyspace
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dmarro89/go-redis-hashtable/structure"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestKeyspace returns a Keyspace whose clock is controlled by the returned function.
+func newTestKeyspace() (*Keyspace, func(time.Duration)) {
+	k := NewKeyspace()
+	now := time.UnixMilli(1_000_000)
+	k.now = func() time.Time { return now }
+	return k, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestSetGetDelete(t *testing.T) {
+	k, _ := newTestKeyspace()
+
+	assert.NoError(t, k.Set("key1", "value1"))
+	assert.Equal(t, "value1", k.Get("key1"), "Unexpected value for key1")
+
+	assert.NoError(t, k.Set("empty", ""))
+	assert.Equal(t, int64(-1), k.TTL("empty"), "A key holding an empty string should exist")
+
+	assert.NoError(t, k.Delete("key1"))
+	assert.Equal(t, "", k.Get("key1"), "Unexpected value for key1 after delete")
+	assert.EqualError(t, k.Delete("key1"), `entry not found`)
+	assert.ErrorIs(t, k.Delete("key1"), structure.ErrKeyNotFound)
+}
+
+func TestLookup(t *testing.T) {
+	k, advance := newTestKeyspace()
+
+	_, found := k.Lookup("missing")
+	assert.False(t, found, "A missing key should not be found")
+
+	assert.NoError(t, k.Set("empty", ""))
+	value, found := k.Lookup("empty")
+	assert.True(t, found, "A key holding an empty string should be found")
+	assert.Equal(t, "", value, "Unexpected value for empty")
+
+	assert.True(t, k.Expire("empty", 10))
+	advance(10 * time.Second)
+	_, found = k.Lookup("empty")
+	assert.False(t, found, "An expired key should not be found")
+}
+
+func TestExpire(t *testing.T) {
+	k, advance := newTestKeyspace()
+
+	assert.False(t, k.Expire("missing", 10), "Expire should fail on a missing key")
+
+	k.Set("key1", "value1")
+	assert.True(t, k.Expire("key1", 10), "Expire should succeed on an existing key")
+	assert.Equal(t, int64(10), k.TTL("key1"), "Unexpected TTL")
+	assert.Equal(t, int64(10000), k.PTTL("key1"), "Unexpected PTTL")
+
+	advance(9 * time.Second)
+	assert.Equal(t, "value1", k.Get("key1"), "Key should not be expired yet")
+	assert.Equal(t, int64(1), k.TTL("key1"), "Unexpected TTL")
+
+	// Lazy expiry on Get
+	advance(time.Second)
+	assert.Equal(t, "", k.Get("key1"), "Key should be expired")
+	assert.Equal(t, int64(-2), k.TTL("key1"), "Expired key should not exist")
+	assert.Equal(t, int64(0), k.expires.Get("key1"), "Expire should be removed with the key")
+
+	// A non positive timeout deletes the key
+	k.Set("key2", "value2")
+	assert.True(t, k.Expire("key2", 0))
+	assert.Equal(t, int64(-2), k.TTL("key2"), "Key should be deleted by a non positive timeout")
+}
+
+func TestPExpireAt(t *testing.T) {
+	k, advance := newTestKeyspace()
+
+	k.Set("key1", "value1")
+	assert.True(t, k.PExpireAt("key1", k.nowMilliseconds()+1500))
+	assert.Equal(t, int64(1500), k.PTTL("key1"), "Unexpected PTTL")
+	assert.Equal(t, int64(2), k.TTL("key1"), "TTL should be rounded to the closest second")
+
+	advance(1500 * time.Millisecond)
+	assert.EqualError(t, k.Delete("key1"), `entry not found`, "Expired key should not be deleted twice")
+}
+
+func TestSetRemovesExpire(t *testing.T) {
+	k, _ := newTestKeyspace()
+
+	k.Set("key1", "value1")
+	k.Expire("key1", 10)
+	k.Set("key1", "value2")
+	assert.Equal(t, int64(-1), k.TTL("key1"), "Set should remove the expire")
+}
+
+func TestPersist(t *testing.T) {
+	k, advance := newTestKeyspace()
+
+	assert.False(t, k.Persist("missing"), "Persist should fail on a missing key")
+
+	k.Set("key1", "value1")
+	assert.False(t, k.Persist("key1"), "Persist should fail on a key without expire")
+
+	k.Expire("key1", 10)
+	assert.True(t, k.Persist("key1"), "Persist should remove the expire")
+	assert.Equal(t, int64(-1), k.TTL("key1"), "Unexpected TTL after persist")
+
+	advance(time.Minute)
+	assert.Equal(t, "value1", k.Get("key1"), "Persistent key should not expire")
+}
+
+func TestActiveExpireCycle(t *testing.T) {
+	k, advance := newTestKeyspace()
+
+	for i := 0; i < 1000; i++ {
+		k.Set(fmt.Sprintf("key%d", i), "value")
+	}
+	for i := 0; i < 500; i++ {
+		k.Expire(fmt.Sprintf("key%d", i), 10)
+	}
+
+	assert.Equal(t, 0, k.ActiveExpireCycle(time.Second), "Unexpected expired keys before the timeout")
+
+	advance(10 * time.Second)
+	deleted := 0
+	for len(k.expires.GetAllItems()) > 0 {
+		deleted += k.ActiveExpireCycle(time.Second)
+	}
+	assert.Equal(t, 500, deleted, "Expected every volatile key to be expired")
+	assert.Len(t, k.dict.GetAllItems(), 500, "Persistent keys should not be expired")
+}
@@ -0,0 +1,797 @@
+package structure
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/dmarro89/go-redis-hashtable/hashing"
+)
+
+const (
+	// BUCKET_SLOTS is the number of entries of a bucket of a BucketDict, so that a bucket
+	// holds 8 bytes of metadata and 7 pointers, the entries and the child bucket: 64 bytes.
+	BUCKET_SLOTS = 6
+	// BUCKET_FULL is the presence bitmap of a bucket whose slots are all filled.
+	BUCKET_FULL = uint8(1)<<BUCKET_SLOTS - 1
+	// BUCKET_MAX_FILL_PERCENT is the fill of the slots above which a BucketDict grows.
+	BUCKET_MAX_FILL_PERCENT = int64(100)
+	// BUCKET_MIN_FILL_PERCENT is the fill of the slots below which a BucketDict shrinks.
+	BUCKET_MIN_FILL_PERCENT = int64(13)
+)
+
+// bucketEntry is an element of a BucketDict, referenced by a slot of a bucket.
+type bucketEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// bucket holds up to BUCKET_SLOTS entries, like the buckets of the Redis hashtable.c.
+// Whatever K and V, a bucket is 64 bytes, the size of a cache line: the presence bitmap,
+// the 6 hash fragments and a padding byte, then the 6 entry pointers and the child pointer.
+// This is the layout of a chained bucket of hashtable.c, which uses its last entry pointer
+// to reference the child bucket. The metadata are read first, so that a lookup only
+// dereferences the entries whose fragment matches.
+type bucket[K comparable, V any] struct {
+	// presence has the bit i set when the slot i holds an entry
+	presence uint8
+	// fragments holds the top 8 bits of the digest of the key of each slot
+	fragments [BUCKET_SLOTS]uint8
+	entries   [BUCKET_SLOTS]*bucketEntry[K, V]
+	// child holds the entries that did not fit in this bucket, it is only allocated when the bucket is full
+	child *bucket[K, V]
+}
+
+// bucketTable is the hash table of a BucketDict: a contiguous array of buckets.
+type bucketTable[K comparable, V any] struct {
+	buckets  []bucket[K, V]
+	sizemask uint64
+	used     int64
+}
+
+// newBucketTable creates a new bucketTable with the specified number of buckets.
+//
+// Parameters:
+// - size: the number of buckets, a power of two or zero.
+//
+// Returns:
+// - *bucketTable: a pointer to the newly created bucketTable.
+func newBucketTable[K comparable, V any](size int64) *bucketTable[K, V] {
+	var sizemask uint64
+	if size > 0 {
+		sizemask = uint64(size - 1)
+	}
+
+	return &bucketTable[K, V]{
+		buckets:  make([]bucket[K, V], size),
+		sizemask: sizemask,
+	}
+}
+
+// capacity returns the number of slots of the buckets of the table, not counting the child buckets.
+func (t *bucketTable[K, V]) capacity() int64 {
+	return int64(len(t.buckets)) * BUCKET_SLOTS
+}
+
+// freeSlot returns a free slot in the chain of buckets at index, appending a child bucket
+// to the chain if all its buckets are full.
+//
+// Parameters:
+// - index: the index of the bucket.
+//
+// Returns:
+// - *bucket: the bucket of the chain holding the free slot.
+// - int: the free slot.
+func (t *bucketTable[K, V]) freeSlot(index uint64) (*bucket[K, V], int) {
+	b := &t.buckets[index]
+	for b.presence == BUCKET_FULL {
+		if b.child == nil {
+			b.child = &bucket[K, V]{}
+		}
+		b = b.child
+	}
+	return b, bits.TrailingZeros8(^b.presence)
+}
+
+// BucketDict is a dictionary whose entries are referenced by the slots of buckets of BUCKET_SLOTS
+// slots rather than linked in a chain of DictEntry, like the Redis hashtable.c that replaced dict.c.
+// Each bucket also stores 8 bits of the digest of each of its keys, so a lookup reads a single
+// cache line and only dereferences the entries whose fragment matches. A full bucket is extended
+// with a child bucket, and entries never move to another bucket index of the same table, so
+// the incremental rehashing and the reverse-binary Scan work like the ones of Dict.
+type BucketDict[K comparable, V any] struct {
+	tables    [2]*bucketTable[K, V]
+	rehashidx int
+	hasher    hashing.IHasher[K]
+}
+
+// NewBucketDict returns a new instance of BucketDict whose keys are hashed by the given hasher.
+//
+// Parameters:
+// - hasher: the hashing.IHasher used to compute the bucket of each key.
+//
+// Returns:
+// - IDict: a pointer to the newly created BucketDict.
+func NewBucketDict[K comparable, V any](hasher hashing.IHasher[K]) IDict[K, V] {
+	return &BucketDict[K, V]{
+		tables:    [2]*bucketTable[K, V]{newBucketTable[K, V](0), newBucketTable[K, V](0)},
+		rehashidx: -1,
+		hasher:    hasher,
+	}
+}
+
+// NewBucketSipHashDict returns a new instance of a string/string BucketDict hashed with SipHash-2-4.
+// Each BucketDict is keyed with its own random seed.
+//
+// The function does not take any parameters.
+// It returns a pointer to BucketDict.
+func NewBucketSipHashDict() IDict[string, string] {
+	return NewBucketDict[string, string](hashing.NewSip24Hasher())
+}
+
+// fragment returns the 8 bits of a digest stored in a bucket. The bucket index is taken
+// from the low bits of the digest, so the fragment is taken from the high bits.
+func fragment(hash uint64) uint8 {
+	return uint8(hash >> 56)
+}
+
+// bucketCount returns the number of buckets, a power of two, needed to hold entries in their slots.
+//
+// Parameters:
+// - entries: the number of entries.
+//
+// Returns:
+// - int64: the number of buckets, at least 1.
+func bucketCount(entries int64) int64 {
+	buckets := (entries + BUCKET_SLOTS - 1) / BUCKET_SLOTS
+	if buckets <= 1 {
+		return 1
+	}
+	return int64(1) << bits.Len64(uint64(buckets-1))
+}
+
+// isRehashing checks if the rehash index of the BucketDict is not equal to -1.
+func (d *BucketDict[K, V]) isRehashing() bool {
+	return d.rehashidx != -1
+}
+
+// expand resizes the dictionary to hold the given number of entries, like Dict.expand.
+// The entries are migrated into the new table by the incremental rehashing.
+//
+// Parameters:
+// - entries: the number of entries the new table must hold.
+//
+// No return values.
+func (d *BucketDict[K, V]) expand(entries int64) {
+	if d.isRehashing() || d.tables[0].used > entries {
+		return
+	}
+
+	// Rehashing to a table of the same size is useless
+	size := bucketCount(entries)
+	if size == int64(len(d.tables[0].buckets)) {
+		return
+	}
+
+	table := newBucketTable[K, V](size)
+	if len(d.tables[0].buckets) == 0 {
+		d.tables[0] = table
+		return
+	}
+
+	d.tables[1] = table
+	d.rehashidx = 0
+}
+
+// expandIfNeeded grows the dictionary when its slots are filled for more than BUCKET_MAX_FILL_PERCENT.
+//
+// No parameters.
+// No return values.
+func (d *BucketDict[K, V]) expandIfNeeded() {
+	if d.isRehashing() {
+		return
+	}
+
+	main := d.tables[0]
+	if len(main.buckets) == 0 {
+		d.expand(INITIAL_SIZE)
+	} else if main.used*100 >= main.capacity()*BUCKET_MAX_FILL_PERCENT {
+		d.expand(main.used * 2)
+	}
+}
+
+// shrinkIfNeeded shrinks the dictionary when its slots are filled for less than BUCKET_MIN_FILL_PERCENT.
+//
+// No parameters.
+// No return values.
+func (d *BucketDict[K, V]) shrinkIfNeeded() {
+	if d.isRehashing() {
+		return
+	}
+
+	main := d.tables[0]
+	if len(main.buckets) > 1 && main.used*100 <= main.capacity()*BUCKET_MIN_FILL_PERCENT {
+		d.expand(main.used)
+	}
+}
+
+// rehash migrates up to n buckets, with their child buckets, from the main table to the
+// rehashing table, visiting at most n*10 empty buckets like Dict.rehash.
+//
+// Parameters:
+// - n: the number of buckets to migrate.
+//
+// Returns:
+// - bool: true if there are still entries to migrate, false otherwise.
+func (d *BucketDict[K, V]) rehash(n int) bool {
+	emptyVisits := n * 10
+	if !d.isRehashing() {
+		return false
+	}
+
+	main, target := d.tables[0], d.tables[1]
+	for n > 0 && main.used != 0 {
+		n--
+
+		for main.buckets[d.rehashidx].presence == 0 && main.buckets[d.rehashidx].child == nil {
+			d.rehashidx++
+			emptyVisits--
+			if emptyVisits == 0 {
+				return true
+			}
+		}
+
+		for b := &main.buckets[d.rehashidx]; b != nil; b = b.child {
+			for slots := b.presence; slots != 0; slots &= slots - 1 {
+				slot := bits.TrailingZeros8(slots)
+				hash := d.hasher.Digest(b.entries[slot].key)
+				free, freeSlot := target.freeSlot(hash & target.sizemask)
+				free.fragments[freeSlot] = fragment(hash)
+				free.entries[freeSlot] = b.entries[slot]
+				free.presence |= 1 << freeSlot
+				main.used--
+				target.used++
+			}
+		}
+
+		main.buckets[d.rehashidx] = bucket[K, V]{}
+		d.rehashidx++
+	}
+
+	if main.used == 0 {
+		shrinking := len(target.buckets) < len(main.buckets)
+		d.tables[0] = target
+		d.tables[1] = newBucketTable[K, V](0)
+		d.rehashidx = -1
+		// Shrink again after a shrinking rehash, see Dict.rehash
+		if shrinking {
+			d.shrinkIfNeeded()
+		}
+		d.debugValidate()
+		return d.isRehashing()
+	}
+
+	d.debugValidate()
+	return true
+}
+
+// RehashSteps performs n steps of incremental rehashing, each one migrating a bucket
+// from the main table to the rehashing table.
+//
+// Parameters:
+// - n: the number of buckets to migrate.
+//
+// Returns:
+// - bool: true if there are still entries to migrate, false otherwise.
+func (d *BucketDict[K, V]) RehashSteps(n int) bool {
+	return d.rehash(n)
+}
+
+// RehashMilliseconds performs incremental rehashing in batches of 100 steps
+// for at most the given amount of milliseconds, like Dict.RehashMilliseconds.
+//
+// Parameters:
+// - ms: the time budget in milliseconds.
+//
+// Returns:
+// - int: the number of rehashing steps performed.
+func (d *BucketDict[K, V]) RehashMilliseconds(ms int) int {
+	return rehashMilliseconds(ms, d.isRehashing, func() { d.rehash(1) })
+}
+
+// find returns the position of a key whose digest is already computed.
+// The buckets of the main table before rehashidx are skipped, since they have been migrated.
+//
+// Parameters:
+// - hash: the digest of the key.
+// - key: the key to search for.
+//
+// Returns:
+// - *bucketTable: the table holding the key.
+// - uint64: the index of the chain of buckets holding the key.
+// - *bucket: the bucket holding the key, or nil if the key is not in the dictionary.
+// - int: the slot of the key in the bucket.
+func (d *BucketDict[K, V]) find(hash uint64, key K) (*bucketTable[K, V], uint64, *bucket[K, V], int) {
+	top := fragment(hash)
+	for i, table := range d.tables {
+		if table.used == 0 {
+			continue
+		}
+
+		index := hash & table.sizemask
+		if i == 0 && d.isRehashing() && index < uint64(d.rehashidx) {
+			continue
+		}
+
+		for b := &table.buckets[index]; b != nil; b = b.child {
+			for slots := b.presence; slots != 0; slots &= slots - 1 {
+				slot := bits.TrailingZeros8(slots)
+				if b.fragments[slot] == top && b.entries[slot].key == key {
+					return table, index, b, slot
+				}
+			}
+		}
+	}
+
+	return nil, 0, nil, 0
+}
+
+// findPositionForInsert looks up a key once, like Dict.findPositionForInsert: it returns the
+// position of the key if it is in the dictionary, or a free slot where the key must be inserted
+// otherwise. The incremental rehashing step and the expansion are only performed when the key
+// is missing, before the free slot is chosen, so that it stays valid for insertAt.
+//
+// Parameters:
+// - key: the key to look up.
+//
+// Returns:
+// - *bucket: the bucket holding the key, or the bucket holding the free slot.
+// - int: the slot of the key, or the free slot, whose fragment is already set.
+// - *bucketTable: the table where the key must be inserted, or nil if the key was found.
+func (d *BucketDict[K, V]) findPositionForInsert(key K) (*bucket[K, V], int, *bucketTable[K, V]) {
+	hash := d.hasher.Digest(key)
+	if _, _, b, slot := d.find(hash, key); b != nil {
+		return b, slot, nil
+	}
+
+	if d.isRehashing() {
+		d.rehash(1)
+	}
+	d.expandIfNeeded()
+
+	// While rehashing, new entries always go to the rehashing table
+	table := d.tables[0]
+	if d.isRehashing() {
+		table = d.tables[1]
+	}
+	b, slot := table.freeSlot(hash & table.sizemask)
+	b.fragments[slot] = fragment(hash)
+	return b, slot, table
+}
+
+// insertAt fills the free slot returned by findPositionForInsert.
+//
+// Parameters:
+// - table: the table returned by findPositionForInsert.
+// - b: the bucket returned by findPositionForInsert.
+// - slot: the free slot returned by findPositionForInsert.
+// - key: the key of the new entry.
+// - value: the value of the new entry.
+//
+// No return values.
+func (d *BucketDict[K, V]) insertAt(table *bucketTable[K, V], b *bucket[K, V], slot int, key K, value V) {
+	b.entries[slot] = &bucketEntry[K, V]{key: key, value: value}
+	b.presence |= 1 << slot
+	table.used++
+	d.debugValidate()
+}
+
+// delete removes a key from the dictionary and returns the value it held.
+// A child bucket left empty is unlinked from its chain; the other entries never move,
+// so that a Scan in progress does not miss them.
+//
+// Parameters:
+// - key: the key to delete.
+//
+// Returns:
+// - V: the value of the deleted key, or the zero value of V if the key was not found.
+// - bool: true if the key was found and deleted, false otherwise.
+func (d *BucketDict[K, V]) delete(key K) (V, bool) {
+	var zero V
+	if d.Len() == 0 {
+		return zero, false
+	}
+
+	if d.isRehashing() {
+		d.rehash(1)
+	}
+
+	table, index, b, slot := d.find(d.hasher.Digest(key), key)
+	if b == nil {
+		return zero, false
+	}
+
+	// The slot is cleared so that the entry can be garbage collected
+	value := b.entries[slot].value
+	b.entries[slot] = nil
+	b.presence &^= 1 << slot
+	table.used--
+
+	// Unlink the child buckets left empty
+	for parent := &table.buckets[index]; parent.child != nil; {
+		if parent.child.presence == 0 {
+			parent.child = parent.child.child
+		} else {
+			parent = parent.child
+		}
+	}
+
+	d.shrinkIfNeeded()
+	d.debugValidate()
+	return value, true
+}
+
+// Get returns the value associated with the given key in the dictionary.
+//
+// Parameters:
+// - key: the key to look up in the dictionary.
+//
+// Return:
+// - V: the value associated with the key, or the zero value of V if the key is not found.
+func (d *BucketDict[K, V]) Get(key K) V {
+	value, _ := d.Lookup(key)
+	return value
+}
+
+// Lookup returns the value associated with the given key in the dictionary and whether it was found.
+//
+// Parameters:
+// - key: the key to look up in the dictionary.
+//
+// Return:
+// - V: the value associated with the key, or the zero value of V if the key is not found.
+// - bool: true if the key is in the dictionary, false otherwise.
+func (d *BucketDict[K, V]) Lookup(key K) (V, bool) {
+	if d.Len() == 0 {
+		var zero V
+		return zero, false
+	}
+
+	_, _, b, slot := d.find(d.hasher.Digest(key), key)
+	if b == nil {
+		var zero V
+		return zero, false
+	}
+	return b.entries[slot].value, true
+}
+
+// Set sets the value of a key in the dictionary.
+//
+// Parameters:
+//   - key: the key to set the value for.
+//   - value: the value to set.
+//
+// Returns:
+//   - error: always nil, since an existing key is updated.
+func (d *BucketDict[K, V]) Set(key K, value V) error {
+	b, slot, table := d.findPositionForInsert(key)
+	if table == nil {
+		b.entries[slot].value = value
+		return nil
+	}
+
+	d.insertAt(table, b, slot, key, value)
+	return nil
+}
+
+// Delete deletes an entry from the dictionary.
+//
+// Parameters:
+// - key: the key of the entry to be deleted.
+//
+// Returns:
+// - error: ErrKeyNotFound if the entry is not found.
+func (d *BucketDict[K, V]) Delete(key K) error {
+	if _, found := d.delete(key); !found {
+		return ErrKeyNotFound
+	}
+	return nil
+}
+
+// SetIfAbsent sets the value of a key only if the key is not in the dictionary, like Redis SETNX.
+//
+// Parameters:
+// - key: the key to set the value for.
+// - value: the value to set.
+//
+// Returns:
+// - bool: true if the key was added, false if it was already in the dictionary.
+func (d *BucketDict[K, V]) SetIfAbsent(key K, value V) bool {
+	b, slot, table := d.findPositionForInsert(key)
+	if table == nil {
+		return false
+	}
+
+	d.insertAt(table, b, slot, key, value)
+	return true
+}
+
+// SetIfPresent sets the value of a key only if the key is already in the dictionary, like Redis SET XX.
+//
+// Parameters:
+// - key: the key to set the value for.
+// - value: the value to set.
+//
+// Returns:
+// - bool: true if the value was updated, false if the key is not in the dictionary.
+func (d *BucketDict[K, V]) SetIfPresent(key K, value V) bool {
+	if d.Len() == 0 {
+		return false
+	}
+
+	_, _, b, slot := d.find(d.hasher.Digest(key), key)
+	if b == nil {
+		return false
+	}
+	b.entries[slot].value = value
+	return true
+}
+
+// GetAndSet sets the value of a key and returns the value it replaced, like Redis GETSET.
+//
+// Parameters:
+// - key: the key to set the value for.
+// - value: the value to set.
+//
+// Returns:
+// - V: the previous value of the key, or the zero value of V if the key was not in the dictionary.
+// - bool: true if the key was already in the dictionary, false if it was added.
+func (d *BucketDict[K, V]) GetAndSet(key K, value V) (V, bool) {
+	b, slot, table := d.findPositionForInsert(key)
+	if table != nil {
+		d.insertAt(table, b, slot, key, value)
+		var zero V
+		return zero, false
+	}
+
+	old := b.entries[slot].value
+	b.entries[slot].value = value
+	return old, true
+}
+
+// GetAndDelete deletes a key and returns the value it held, like Redis GETDEL.
+//
+// Parameters:
+// - key: the key to delete.
+//
+// Returns:
+// - V: the value of the deleted key, or the zero value of V if the key was not found.
+// - bool: true if the key was found and deleted, false otherwise.
+func (d *BucketDict[K, V]) GetAndDelete(key K) (V, bool) {
+	return d.delete(key)
+}
+
+// CompareAndSwap sets the value of a key to new only if its current value is equal to old,
+// like sync.Map.CompareAndSwap. It panics if the values are not comparable.
+//
+// Parameters:
+// - key: the key to update.
+// - old: the expected current value.
+// - new: the value to set.
+//
+// Returns:
+// - bool: true if the value was swapped, false if the key is missing or holds another value.
+func (d *BucketDict[K, V]) CompareAndSwap(key K, old, new V) bool {
+	if d.Len() == 0 {
+		return false
+	}
+
+	_, _, b, slot := d.find(d.hasher.Digest(key), key)
+	if b == nil || any(b.entries[slot].value) != any(old) {
+		return false
+	}
+	b.entries[slot].value = new
+	return true
+}
+
+// Upsert sets the value of a key to the result of fn, called with the current value of the key
+// and whether it exists, looking up the key once.
+//
+// Parameters:
+// - key: the key to update or insert.
+// - fn: the function computing the new value, it must not modify the dictionary.
+//
+// Returns:
+// - V: the new value of the key.
+func (d *BucketDict[K, V]) Upsert(key K, fn func(old V, exists bool) V) V {
+	b, slot, table := d.findPositionForInsert(key)
+	if table == nil {
+		value := fn(b.entries[slot].value, true)
+		b.entries[slot].value = value
+		return value
+	}
+
+	var zero V
+	value := fn(zero, false)
+	d.insertAt(table, b, slot, key, value)
+	return value
+}
+
+// Len returns the number of entries of both tables.
+func (d *BucketDict[K, V]) Len() int {
+	return int(d.tables[0].used + d.tables[1].used)
+}
+
+// GetAllItems returns a map holding every element of both tables.
+func (d *BucketDict[K, V]) GetAllItems() map[K]V {
+	items := make(map[K]V, d.Len())
+	for _, table := range d.tables {
+		for index := range table.buckets {
+			scanBuckets(&table.buckets[index], func(key K, value V) { items[key] = value })
+		}
+	}
+	return items
+}
+
+// Scan iterates incrementally over the elements of the dictionary, like Dict.Scan: each call
+// visits a chain of buckets (or, while rehashing, a chain of the smaller table and all the chains
+// of the larger table that expand from it) and returns the cursor to use for the next call.
+// The iteration is complete when the returned cursor is 0. Every element present for the whole
+// duration of the scan is returned at least once; elements may be returned more than once.
+// fn must not modify the dictionary.
+//
+// Parameters:
+// - cursor: the cursor returned by the previous call, or 0 to start a new iteration.
+// - fn: the function called for each element of the visited buckets.
+//
+// Returns:
+// - uint64: the cursor for the next call, or 0 if the iteration is complete.
+func (d *BucketDict[K, V]) Scan(cursor uint64, fn func(key K, value V)) uint64 {
+	if d.Len() == 0 {
+		return 0
+	}
+
+	if !d.isRehashing() {
+		table := d.tables[0]
+		scanBuckets(&table.buckets[cursor&table.sizemask], fn)
+		return nextCursor(cursor, table.sizemask)
+	}
+
+	small, large := d.tables[0], d.tables[1]
+	if len(small.buckets) > len(large.buckets) {
+		small, large = large, small
+	}
+	smallMask, largeMask := small.sizemask, large.sizemask
+
+	// Emit the chain of the smaller table pointed by the cursor
+	scanBuckets(&small.buckets[cursor&smallMask], fn)
+
+	// Emit all the chains of the larger table that are expansions of the chain of the smaller one
+	for {
+		scanBuckets(&large.buckets[cursor&largeMask], fn)
+		cursor = nextCursor(cursor, largeMask)
+		if cursor&(smallMask^largeMask) == 0 {
+			break
+		}
+	}
+
+	return cursor
+}
+
+// scanBuckets calls fn for each entry of the chain of buckets starting at b.
+func scanBuckets[K comparable, V any](b *bucket[K, V], fn func(key K, value V)) {
+	for ; b != nil; b = b.child {
+		for slots := b.presence; slots != 0; slots &= slots - 1 {
+			slot := bits.TrailingZeros8(slots)
+			fn(b.entries[slot].key, b.entries[slot].value)
+		}
+	}
+}
+
+// Validate checks the structural invariants of the BucketDict, like Dict.Validate: the size
+// and the sizemask of each table, that every entry is in the chain of its digest with the right
+// hash fragment, that no key appears twice, that no child bucket is empty, that the used counters
+// match the entries, and that the rehashing index is consistent with the migrated buckets.
+//
+// No parameters.
+//
+// Returns:
+// - error: an error wrapping ErrInvalidDict describing the first violation found, or nil.
+func (d *BucketDict[K, V]) Validate() error {
+	seen := make(map[K]struct{}, d.Len())
+	for i, table := range d.tables {
+		if err := d.validateTable(i, table, seen); err != nil {
+			return err
+		}
+	}
+
+	main := d.tables[0]
+	if !d.isRehashing() {
+		if len(d.tables[1].buckets) != 0 || d.tables[1].used != 0 {
+			return fmt.Errorf(`%w: rehashing table of size %d with %d entries while not rehashing`,
+				ErrInvalidDict, len(d.tables[1].buckets), d.tables[1].used)
+		}
+		return nil
+	}
+
+	if d.rehashidx < 0 || d.rehashidx > len(main.buckets) {
+		return fmt.Errorf(`%w: rehashidx %d out of the main table of size %d`, ErrInvalidDict, d.rehashidx, len(main.buckets))
+	}
+	if len(d.tables[1].buckets) == 0 {
+		return fmt.Errorf(`%w: rehashing without a rehashing table`, ErrInvalidDict)
+	}
+	for index := 0; index < d.rehashidx; index++ {
+		if main.buckets[index].presence != 0 || main.buckets[index].child != nil {
+			return fmt.Errorf(`%w: bucket %d of the main table not empty before rehashidx %d`, ErrInvalidDict, index, d.rehashidx)
+		}
+	}
+
+	return nil
+}
+
+// validateTable checks the invariants of one of the two tables of a BucketDict, see Validate.
+//
+// Parameters:
+// - tableIndex: the index of the table in tables.
+// - table: the table to check.
+// - seen: the keys found in the tables already checked, updated with the keys of this table.
+//
+// Returns:
+// - error: an error wrapping ErrInvalidDict describing the first violation found, or nil.
+func (d *BucketDict[K, V]) validateTable(tableIndex int, table *bucketTable[K, V], seen map[K]struct{}) error {
+	size := len(table.buckets)
+	if size == 0 {
+		if table.used != 0 {
+			return fmt.Errorf(`%w: table %d is empty but has used %d`, ErrInvalidDict, tableIndex, table.used)
+		}
+		return nil
+	}
+	if bits.OnesCount64(uint64(size)) != 1 || table.sizemask != uint64(size-1) {
+		return fmt.Errorf(`%w: table %d has size %d and sizemask %d`, ErrInvalidDict, tableIndex, size, table.sizemask)
+	}
+
+	count := int64(0)
+	for index := range table.buckets {
+		for b := &table.buckets[index]; b != nil; b = b.child {
+			if b.presence&^BUCKET_FULL != 0 {
+				return fmt.Errorf(`%w: bucket %d of table %d has presence %b`, ErrInvalidDict, index, tableIndex, b.presence)
+			}
+			if b != &table.buckets[index] && b.presence == 0 {
+				return fmt.Errorf(`%w: bucket %d of table %d has an empty child bucket`, ErrInvalidDict, index, tableIndex)
+			}
+			for slot := range b.entries {
+				if b.presence&(1<<slot) == 0 && b.entries[slot] != nil {
+					return fmt.Errorf(`%w: free slot %d of bucket %d of table %d holds an entry`, ErrInvalidDict, slot, index, tableIndex)
+				}
+			}
+
+			for slots := b.presence; slots != 0; slots &= slots - 1 {
+				slot := bits.TrailingZeros8(slots)
+				if b.entries[slot] == nil {
+					return fmt.Errorf(`%w: slot %d of bucket %d of table %d is present without an entry`, ErrInvalidDict, slot, index, tableIndex)
+				}
+				key := b.entries[slot].key
+				count++
+
+				hash := d.hasher.Digest(key)
+				if hash&table.sizemask != uint64(index) {
+					return fmt.Errorf(`%w: key %v of table %d in bucket %d instead of %d`,
+						ErrInvalidDict, key, tableIndex, index, hash&table.sizemask)
+				}
+				if b.fragments[slot] != fragment(hash) {
+					return fmt.Errorf(`%w: key %v of table %d has fragment %d instead of %d`,
+						ErrInvalidDict, key, tableIndex, b.fragments[slot], fragment(hash))
+				}
+				if _, found := seen[key]; found {
+					return fmt.Errorf(`%w: key %v twice`, ErrInvalidDict, key)
+				}
+				seen[key] = struct{}{}
+			}
+		}
+	}
+
+	if count != table.used {
+		return fmt.Errorf(`%w: table %d has used %d but %d entries`, ErrInvalidDict, tableIndex, table.used, count)
+	}
+
+	return nil
+}
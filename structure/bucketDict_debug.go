@@ -0,0 +1,15 @@
+//go:build dictdebug
+
+package structure
+
+import "math/bits"
+
+// debugValidate validates the BucketDict like Dict.debugValidate.
+func (d *BucketDict[K, V]) debugValidate() {
+	if bits.OnesCount64(uint64(d.Len())) > 1 {
+		return
+	}
+	if err := d.Validate(); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !dictdebug
+
+package structure
+
+// debugValidate does nothing unless built with the dictdebug tag, see bucketDict_debug.go.
+func (d *BucketDict[K, V]) debugValidate() {}
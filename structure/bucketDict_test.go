@@ -0,0 +1,197 @@
+package structure
+
+import (
+	"fmt"
+	"testing"
+	"unsafe"
+
+	"github.com/dmarro89/go-redis-hashtable/hashing"
+	"github.com/stretchr/testify/assert"
+)
+
+// chainLength returns the number of buckets of the chain at index.
+func chainLength[K comparable, V any](table *bucketTable[K, V], index uint64) int {
+	length := 0
+	for b := &table.buckets[index]; b != nil; b = b.child {
+		length++
+	}
+	return length
+}
+
+func TestBucketDict(t *testing.T) {
+	d := NewBucketSipHashDict()
+
+	assert.Equal(t, "", d.Get("key1"), "Unexpected value for nonexistent key")
+	assert.NoError(t, d.Set("key1", "value1"))
+	assert.Equal(t, "value1", d.Get("key1"), "Unexpected value for key1")
+	assert.NoError(t, d.Set("key1", "updated"))
+	assert.Equal(t, "updated", d.Get("key1"), "Unexpected value for key1 after update")
+	assert.Equal(t, 1, d.Len(), "Unexpected length after update")
+
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i)))
+	}
+	assert.Equal(t, 1000, d.Len(), "Unexpected length")
+	for i := 0; i < 1000; i++ {
+		assert.Equal(t, fmt.Sprintf("value%d", i), d.Get(fmt.Sprintf("key%d", i)), "Unexpected value for key%d", i)
+	}
+	assert.Len(t, d.GetAllItems(), 1000, "Unexpected number of items")
+	assert.NoError(t, d.(*BucketDict[string, string]).Validate())
+
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, d.Delete(fmt.Sprintf("key%d", i)))
+	}
+	assert.ErrorIs(t, d.Delete("key0"), ErrKeyNotFound)
+	assert.Equal(t, 0, d.Len(), "Unexpected length after deleting every key")
+	assert.Empty(t, d.GetAllItems(), "Unexpected items after deleting every key")
+}
+
+func TestBucketSize(t *testing.T) {
+	// A bucket is a cache line whatever the types of the keys and the values
+	assert.Equal(t, uintptr(64), unsafe.Sizeof(bucket[string, string]{}), "Unexpected size of a string/string bucket")
+	assert.Equal(t, uintptr(64), unsafe.Sizeof(bucket[uint8, int]{}), "Unexpected size of a uint8/int bucket")
+	assert.Equal(t, uintptr(64), unsafe.Sizeof(bucket[[4]uint64, []byte]{}), "Unexpected size of a [4]uint64/[]byte bucket")
+}
+
+func TestBucketCount(t *testing.T) {
+	assert.Equal(t, int64(1), bucketCount(0), "Unexpected number of buckets for 0 entries")
+	assert.Equal(t, int64(1), bucketCount(BUCKET_SLOTS), "Unexpected number of buckets for a full bucket")
+	assert.Equal(t, int64(2), bucketCount(BUCKET_SLOTS+1), "Unexpected number of buckets for a full bucket and one more entry")
+	assert.Equal(t, int64(4), bucketCount(3*BUCKET_SLOTS), "Unexpected number of buckets for 3 full buckets")
+	assert.Equal(t, int64(256), bucketCount(1000), "Unexpected number of buckets for 1000 entries")
+}
+
+func TestBucketDict_ChildBuckets(t *testing.T) {
+	// Every key is in bucket 0, with a different fragment
+	hasher := hashing.HasherFunc[int](func(key int) uint64 { return uint64(key) << 56 })
+	d := NewBucketDict[int, int](hasher).(*BucketDict[int, int])
+	d.expand(1 << 10)
+
+	for i := 0; i < 3*BUCKET_SLOTS; i++ {
+		d.Set(i, i)
+	}
+	assert.Equal(t, 3, chainLength(d.tables[0], 0), "A full bucket should be extended with a child bucket")
+	for i := 0; i < 3*BUCKET_SLOTS; i++ {
+		assert.Equal(t, i, d.Get(i), "Unexpected value for key %d", i)
+	}
+	assert.NoError(t, d.Validate())
+
+	// Emptying the last child unlinks it, emptying the first bucket keeps its child
+	for i := 2 * BUCKET_SLOTS; i < 3*BUCKET_SLOTS; i++ {
+		assert.NoError(t, d.Delete(i))
+	}
+	assert.Equal(t, 2, chainLength(d.tables[0], 0), "An empty child bucket should be unlinked")
+	for i := 0; i < BUCKET_SLOTS; i++ {
+		assert.NoError(t, d.Delete(i))
+	}
+	assert.Equal(t, 2, chainLength(d.tables[0], 0), "The first bucket of a chain should stay in the table")
+	assert.NoError(t, d.Validate())
+
+	// Free slots of the first bucket are reused before the child
+	d.Set(100, 100)
+	assert.Equal(t, uint8(1), d.tables[0].buckets[0].presence, "New keys should fill the first free slot of the chain")
+	assert.Equal(t, BUCKET_SLOTS+1, d.Len(), "Unexpected length")
+}
+
+func TestBucketDict_Fragments(t *testing.T) {
+	// Keys 0 and 256 have the same digest, keys 0 and 1 only differ by their fragment
+	hasher := hashing.HasherFunc[int](func(key int) uint64 { return uint64(key%256) << 56 })
+	d := NewBucketDict[int, string](hasher).(*BucketDict[int, string])
+
+	d.Set(0, "zero")
+	d.Set(1, "one")
+	d.Set(256, "collision")
+	assert.Equal(t, "zero", d.Get(0), "Unexpected value for key 0")
+	assert.Equal(t, "one", d.Get(1), "Unexpected value for key 1")
+	assert.Equal(t, "collision", d.Get(256), "Keys with the same fragment should be compared")
+	_, found := d.Lookup(512)
+	assert.False(t, found, "A missing key with a matching fragment should not be found")
+	assert.NoError(t, d.Validate())
+}
+
+func TestBucketDict_Rehashing(t *testing.T) {
+	d := NewBucketSipHashDict().(*BucketDict[string, string])
+	for i := 0; i < 1000; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	d.RehashMilliseconds(1000)
+	assert.False(t, d.isRehashing(), "Expected the rehashing to be complete")
+
+	d.expand(1 << 12)
+	d.rehash(8)
+	assert.True(t, d.isRehashing(), "Expected the dictionary to be rehashing")
+	for i := 0; i < 1000; i++ {
+		assert.Equal(t, fmt.Sprintf("value%d", i), d.Get(fmt.Sprintf("key%d", i)), "Unexpected value for key%d during rehashing", i)
+	}
+	for i := 1000; i < 1100; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	assert.NoError(t, d.Validate())
+
+	d.RehashMilliseconds(1000)
+	assert.False(t, d.isRehashing(), "Expected the rehashing to be complete")
+	assert.Equal(t, 1100, d.Len(), "Unexpected length")
+	assert.NoError(t, d.Validate())
+}
+
+func TestBucketDict_Shrink(t *testing.T) {
+	d := NewBucketSipHashDict().(*BucketDict[string, string])
+	for i := 0; i < 1000; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	for i := 0; i < 990; i++ {
+		d.Delete(fmt.Sprintf("key%d", i))
+	}
+	d.RehashMilliseconds(1000)
+
+	assert.False(t, d.isRehashing(), "Expected the rehashing to be complete")
+	assert.True(t, len(d.tables[0].buckets) < 256, "Expected the table to shrink after mass deletes")
+	for i := 990; i < 1000; i++ {
+		assert.Equal(t, fmt.Sprintf("value%d", i), d.Get(fmt.Sprintf("key%d", i)), "Unexpected value for key%d", i)
+	}
+	assert.NoError(t, d.Validate())
+}
+
+func TestBucketDict_ScanDuringResize(t *testing.T) {
+	d := NewBucketSipHashDict().(*BucketDict[string, string])
+	for i := 0; i < 500; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	d.RehashMilliseconds(1000)
+
+	// The keys below 250 are present for the whole scan, while the table grows and shrinks
+	scanned := make(map[string]bool)
+	cursor, calls := uint64(0), 0
+	for {
+		cursor = d.Scan(cursor, func(key, value string) { scanned[key] = true })
+		if cursor == 0 {
+			break
+		}
+		calls++
+		switch calls {
+		case 10:
+			for i := 500; i < 2000; i++ {
+				d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+			}
+		case 20:
+			for i := 250; i < 2000; i++ {
+				d.Delete(fmt.Sprintf("key%d", i))
+			}
+		default:
+			d.RehashSteps(1)
+		}
+	}
+
+	for i := 0; i < 250; i++ {
+		assert.True(t, scanned[fmt.Sprintf("key%d", i)], "Scan should return key%d, present for the whole scan", i)
+	}
+}
+
+func TestBucketDict_ValidateDetectsCorruption(t *testing.T) {
+	d := NewBucketSipHashDict().(*BucketDict[string, string])
+	d.Set("key1", "value1")
+	assert.NoError(t, d.Validate())
+
+	d.tables[0].buckets[0].fragments[0]++
+	assert.ErrorIs(t, d.Validate(), ErrInvalidDict, "A wrong fragment should be detected")
+}
@@ -0,0 +1,136 @@
+package structure
+
+import (
+	"bytes"
+
+	"github.com/dmarro89/go-redis-hashtable/hashing"
+	"github.com/dmarro89/go-redis-hashtable/utility"
+)
+
+// IBytesDict is a dictionary of byte slice values whose keys can also be given as byte slices,
+// so that keys read from a network buffer are looked up without being converted to strings.
+type IBytesDict interface {
+	IDict[string, []byte]
+	SetBytes(key, value []byte) error
+	GetBytes(key []byte) []byte
+	LookupBytes(key []byte) ([]byte, bool)
+	DeleteBytes(key []byte) error
+}
+
+// BytesDict is a Dict of byte slice values hashed by an IBytesHasher.
+// The values are copied when they are set, so the caller can reuse its buffers, and the
+// values returned by Get, GetBytes and LookupBytes are shared with the dictionary and
+// its snapshots, so they must not be modified.
+type BytesDict struct {
+	*Dict[string, []byte]
+	// bytesHasher is the hasher of the Dict, kept here to hash byte slice keys directly
+	bytesHasher hashing.IBytesHasher
+}
+
+// NewBytesDict returns a new instance of BytesDict whose keys are hashed by the given hasher.
+//
+// Parameters:
+// - hasher: the hashing.IBytesHasher used to compute the bucket of each key.
+//
+// Returns:
+// - IBytesDict: a pointer to the newly created BytesDict.
+func NewBytesDict(hasher hashing.IBytesHasher) IBytesDict {
+	dict := NewDictWithType(&DictType[string, []byte]{
+		HashFunction: hasher,
+		ValDup:       bytes.Clone,
+	}).(*Dict[string, []byte])
+	return &BytesDict{Dict: dict, bytesHasher: hasher}
+}
+
+// NewSipHashBytesDict returns a new instance of BytesDict hashed with SipHash-2-4.
+// Each BytesDict is keyed with its own random seed.
+//
+// The function does not take any parameters.
+// It returns a pointer to BytesDict.
+func NewSipHashBytesDict() IBytesDict {
+	return NewBytesDict(hashing.NewSip24Hasher().(hashing.IBytesHasher))
+}
+
+// SetBytes sets the value of a key given as a byte slice. The key is only converted
+// to a string when a new entry is inserted, so overwriting a value does not copy it.
+//
+// Parameters:
+// - key: the key to set the value for.
+// - value: the value to set, copied by the dictionary.
+//
+// Returns:
+// - error: always nil, since an existing key is updated.
+func (d *BytesDict) SetBytes(key, value []byte) error {
+	entry, hashTable, index := d.findPositionForHash(d.bytesHasher.DigestBytes(key), utility.UnsafeString(key))
+	if entry != nil {
+		d.updateEntry(entry, value)
+		return nil
+	}
+
+	d.insertAt(hashTable, index, string(key), value)
+	return nil
+}
+
+// GetBytes returns the value associated with a key given as a byte slice, without allocating.
+//
+// Parameters:
+// - key: the key to look up in the dictionary.
+//
+// Return:
+// - []byte: the value associated with the key, or nil if the key is not found.
+func (d *BytesDict) GetBytes(key []byte) []byte {
+	value, _ := d.LookupBytes(key)
+	return value
+}
+
+// LookupBytes returns the value associated with a key given as a byte slice and whether it was found,
+// without allocating.
+//
+// Parameters:
+// - key: the key to look up in the dictionary.
+//
+// Return:
+// - []byte: the value associated with the key, or nil if the key is not found.
+// - bool: true if the key is in the dictionary, false otherwise.
+func (d *BytesDict) LookupBytes(key []byte) ([]byte, bool) {
+	if d.mainTable().used == 0 && d.rehashingTable().used == 0 {
+		return nil, false
+	}
+
+	entry := d.findEntry(d.bytesHasher.DigestBytes(key), utility.UnsafeString(key))
+	if entry == nil {
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// DeleteBytes deletes the entry of a key given as a byte slice.
+//
+// Parameters:
+// - key: the key of the entry to be deleted.
+//
+// Returns:
+// - error: ErrKeyNotFound if the entry is not found.
+func (d *BytesDict) DeleteBytes(key []byte) error {
+	return d.Delete(utility.UnsafeString(key))
+}
+
+// CompareAndSwap sets the value of a key to new only if its current value holds the same bytes as old.
+// It shadows Dict.CompareAndSwap, which panics on byte slices since they are not comparable with ==.
+//
+// Parameters:
+// - key: the key to update.
+// - old: the value the key is expected to hold.
+// - new: the value to set, copied by the dictionary.
+//
+// Returns:
+// - bool: true if the value was swapped, false if the key is missing or holds another value.
+func (d *BytesDict) CompareAndSwap(key string, old, new []byte) bool {
+	entry := d.getEntry(key)
+	if entry == nil || !bytes.Equal(entry.value, old) {
+		return false
+	}
+
+	d.updateEntry(entry, new)
+	return true
+}
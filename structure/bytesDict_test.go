@@ -0,0 +1,109 @@
+package structure
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBytesDict_SetGetBytes(t *testing.T) {
+	d := NewSipHashBytesDict()
+
+	assert.NoError(t, d.SetBytes([]byte("key1"), []byte("value1")))
+	assert.Equal(t, []byte("value1"), d.GetBytes([]byte("key1")), "Unexpected value for key1")
+	assert.Equal(t, []byte("value1"), d.Get("key1"), "Keys set as bytes should be found as strings")
+
+	assert.NoError(t, d.Set("key2", []byte("value2")))
+	assert.Equal(t, []byte("value2"), d.GetBytes([]byte("key2")), "Keys set as strings should be found as bytes")
+
+	assert.NoError(t, d.SetBytes([]byte("key1"), []byte("updated")))
+	assert.Equal(t, []byte("updated"), d.GetBytes([]byte("key1")), "Unexpected value for the updated key1")
+	assert.Equal(t, 2, d.Len(), "Updating a key should not add an entry")
+}
+
+func TestBytesDict_LookupBytes(t *testing.T) {
+	d := NewSipHashBytesDict()
+
+	value, found := d.LookupBytes([]byte("key1"))
+	assert.False(t, found, "A key should not be found in an empty dictionary")
+	assert.Nil(t, value, "Unexpected value for a missing key")
+
+	d.SetBytes([]byte("key1"), []byte{})
+	value, found = d.LookupBytes([]byte("key1"))
+	assert.True(t, found, "A key holding an empty value should be found")
+	assert.Empty(t, value, "Unexpected value for key1")
+
+	_, found = d.LookupBytes([]byte("key2"))
+	assert.False(t, found, "A missing key should not be found")
+	assert.Nil(t, d.GetBytes([]byte("key2")), "Unexpected value for a missing key")
+}
+
+func TestBytesDict_ReusedBuffers(t *testing.T) {
+	d := NewSipHashBytesDict()
+	key := []byte("key1")
+	value := []byte("value1")
+	d.SetBytes(key, value)
+
+	copy(key, "key2")
+	copy(value, "value2")
+	assert.Equal(t, []byte("value1"), d.Get("key1"), "The dictionary should keep its own copy of the key and the value")
+	assert.Nil(t, d.Get("key2"), "Modifying the key buffer should not change the stored key")
+}
+
+func TestBytesDict_DeleteBytes(t *testing.T) {
+	d := NewSipHashBytesDict()
+	d.SetBytes([]byte("key1"), []byte("value1"))
+
+	assert.NoError(t, d.DeleteBytes([]byte("key1")))
+	assert.Nil(t, d.GetBytes([]byte("key1")), "Deleted key should not be found")
+	assert.ErrorIs(t, d.DeleteBytes([]byte("key1")), ErrKeyNotFound, "Deleting a missing key should fail")
+}
+
+func TestBytesDict_CompareAndSwap(t *testing.T) {
+	d := NewSipHashBytesDict()
+	d.SetBytes([]byte("key1"), []byte("value1"))
+
+	assert.False(t, d.CompareAndSwap("key1", []byte("other"), []byte("updated")), "A different value should not be swapped")
+	assert.True(t, d.CompareAndSwap("key1", []byte("value1"), []byte("updated")), "Values with the same bytes should be swapped")
+	assert.Equal(t, []byte("updated"), d.Get("key1"), "Unexpected value after the swap")
+	assert.False(t, d.CompareAndSwap("key2", nil, []byte("value2")), "A missing key should not be swapped")
+}
+
+func TestBytesDict_Rehashing(t *testing.T) {
+	d := NewSipHashBytesDict().(*BytesDict)
+	for i := 0; i < 1000; i++ {
+		d.SetBytes([]byte(fmt.Sprintf("key%d", i)), []byte(fmt.Sprintf("value%d", i)))
+	}
+	completeRehashing(d.Dict)
+	d.expand(1 << 12)
+	d.rehash(8)
+	assert.True(t, d.isRehashing(), "Expected the dictionary to be rehashing")
+
+	for i := 0; i < 1000; i++ {
+		key := []byte(fmt.Sprintf("key%d", i))
+		assert.Equal(t, []byte(fmt.Sprintf("value%d", i)), d.GetBytes(key), "Unexpected value for %s", key)
+	}
+	assert.NoError(t, d.Validate())
+}
+
+func TestBytesDict_Snapshot(t *testing.T) {
+	d := NewSipHashBytesDict().(*BytesDict)
+	d.SetBytes([]byte("key1"), []byte("value1"))
+	s := d.Snapshot()
+
+	d.SetBytes([]byte("key1"), []byte("updated"))
+	assert.Equal(t, []byte("value1"), s.Get("key1"), "Snapshot should not see values set as bytes after it was taken")
+	assert.Equal(t, []byte("updated"), d.GetBytes([]byte("key1")), "Unexpected value in the live dictionary")
+}
+
+func TestBytesDict_SetBytesOverwriteDoesNotCopyKey(t *testing.T) {
+	d := NewSipHashBytesDict()
+	key := []byte("key1")
+	value := []byte("value2")
+	d.SetBytes(key, []byte("value1"))
+
+	// Only the value is copied when the key already exists
+	allocs := testing.AllocsPerRun(100, func() { d.SetBytes(key, value) })
+	assert.Equal(t, float64(1), allocs, "Overwriting a value should only copy the value")
+}
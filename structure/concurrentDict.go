@@ -0,0 +1,348 @@
+package structure
+
+import (
+	"sync"
+
+	"github.com/dmarro89/go-redis-hashtable/hashing"
+)
+
+// ConcurrentDict is a Dict safe for concurrent use by multiple goroutines.
+//
+// Readers share a sync.RWMutex, so Get, Scan and GetAllItems run in parallel,
+// while writers are serialized. Reading a Dict never changes its structure (only
+// writes expand the tables and advance the incremental rehashing), which is what
+// makes the shared read lock sufficient.
+type ConcurrentDict[K comparable, V any] struct {
+	mu   sync.RWMutex
+	dict *Dict[K, V]
+}
+
+// NewConcurrentDict returns a new instance of ConcurrentDict whose keys are hashed by the given hasher.
+//
+// Parameters:
+// - hasher: the hashing.IHasher used to compute the bucket of each key.
+//
+// Returns:
+// - IDict: a pointer to the newly created ConcurrentDict.
+func NewConcurrentDict[K comparable, V any](hasher hashing.IHasher[K]) IDict[K, V] {
+	return &ConcurrentDict[K, V]{
+		dict: NewDict[K, V](hasher).(*Dict[K, V]),
+	}
+}
+
+// NewConcurrentSipHashDict returns a new instance of a string/string ConcurrentDict hashed with SipHash-2-4.
+//
+// The function does not take any parameters.
+// It returns a pointer to ConcurrentDict.
+func NewConcurrentSipHashDict() IDict[string, string] {
+	return NewConcurrentDict[string, string](hashing.NewSip24Hasher())
+}
+
+// Get returns the value associated with the given key in the dictionary.
+//
+// Parameters:
+// - key: the key to look up in the dictionary.
+//
+// Return:
+// - V: the value associated with the key, or the zero value of V if the key is not found.
+func (c *ConcurrentDict[K, V]) Get(key K) V {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.dict.Get(key)
+}
+
+// Lookup returns the value associated with the given key and whether it was found.
+//
+// Parameters:
+// - key: the key to look up in the dictionary.
+//
+// Return:
+// - V: the value associated with the key, or the zero value of V if the key is not found.
+// - bool: true if the key is in the dictionary, false otherwise.
+func (c *ConcurrentDict[K, V]) Lookup(key K) (V, bool) {
+	return c.lookupHash(c.dict.hasher.Digest(key), key)
+}
+
+// lookupHash is Lookup for a key whose digest is already computed.
+//
+// Parameters:
+// - hash: the digest of the key.
+// - key: the key to look up in the dictionary.
+//
+// Return:
+// - V: the value associated with the key, or the zero value of V if the key is not found.
+// - bool: true if the key is in the dictionary, false otherwise.
+func (c *ConcurrentDict[K, V]) lookupHash(hash uint64, key K) (V, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.dict.lookupHash(hash, key)
+}
+
+// Set sets the value of a key in the dictionary.
+//
+// Parameters:
+//   - key: the key to set the value for.
+//   - value: the value to set.
+//
+// Returns:
+//   - error: always nil, since an existing key is updated.
+func (c *ConcurrentDict[K, V]) Set(key K, value V) error {
+	return c.setHash(c.dict.hasher.Digest(key), key, value)
+}
+
+// setHash is Set for a key whose digest is already computed.
+//
+// Parameters:
+//   - hash: the digest of the key.
+//   - key: the key to set the value for.
+//   - value: the value to set.
+//
+// Returns:
+//   - error: always nil, since an existing key is updated.
+func (c *ConcurrentDict[K, V]) setHash(hash uint64, key K, value V) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.dict.setHash(hash, key, value)
+}
+
+// Delete deletes an entry from the dictionary.
+//
+// Parameters:
+// - key: the key of the entry to be deleted.
+//
+// Returns:
+// - error: ErrKeyNotFound if the entry is not found.
+func (c *ConcurrentDict[K, V]) Delete(key K) error {
+	return c.deleteHash(c.dict.hasher.Digest(key), key)
+}
+
+// deleteHash is Delete for a key whose digest is already computed.
+//
+// Parameters:
+// - hash: the digest of the key.
+// - key: the key of the entry to be deleted.
+//
+// Returns:
+// - error: ErrKeyNotFound if the entry is not found.
+func (c *ConcurrentDict[K, V]) deleteHash(hash uint64, key K) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.dict.deleteHash(hash, key)
+}
+
+// SetIfAbsent sets the value of a key only if the key is not in the dictionary, see Dict.SetIfAbsent.
+//
+// Parameters:
+// - key: the key to set the value for.
+// - value: the value to set.
+//
+// Returns:
+// - bool: true if the key was added, false if it was already in the dictionary.
+func (c *ConcurrentDict[K, V]) SetIfAbsent(key K, value V) bool {
+	return c.setIfAbsentHash(c.dict.hasher.Digest(key), key, value)
+}
+
+// setIfAbsentHash is SetIfAbsent for a key whose digest is already computed.
+//
+// Parameters:
+// - hash: the digest of the key.
+// - key: the key to set the value for.
+// - value: the value to set.
+//
+// Returns:
+// - bool: true if the key was added, false if it was already in the dictionary.
+func (c *ConcurrentDict[K, V]) setIfAbsentHash(hash uint64, key K, value V) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.dict.setIfAbsentHash(hash, key, value)
+}
+
+// SetIfPresent sets the value of a key only if the key is already in the dictionary, see Dict.SetIfPresent.
+//
+// Parameters:
+// - key: the key to set the value for.
+// - value: the value to set.
+//
+// Returns:
+// - bool: true if the value was updated, false if the key is not in the dictionary.
+func (c *ConcurrentDict[K, V]) SetIfPresent(key K, value V) bool {
+	return c.setIfPresentHash(c.dict.hasher.Digest(key), key, value)
+}
+
+// setIfPresentHash is SetIfPresent for a key whose digest is already computed.
+//
+// Parameters:
+// - hash: the digest of the key.
+// - key: the key to set the value for.
+// - value: the value to set.
+//
+// Returns:
+// - bool: true if the value was updated, false if the key is not in the dictionary.
+func (c *ConcurrentDict[K, V]) setIfPresentHash(hash uint64, key K, value V) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.dict.setIfPresentHash(hash, key, value)
+}
+
+// GetAndSet sets the value of a key and returns the value it replaced, see Dict.GetAndSet.
+//
+// Parameters:
+// - key: the key to set the value for.
+// - value: the value to set.
+//
+// Returns:
+// - V: the previous value of the key, or the zero value of V if the key was not in the dictionary.
+// - bool: true if the key was already in the dictionary, false if it was added.
+func (c *ConcurrentDict[K, V]) GetAndSet(key K, value V) (V, bool) {
+	return c.getAndSetHash(c.dict.hasher.Digest(key), key, value)
+}
+
+// getAndSetHash is GetAndSet for a key whose digest is already computed.
+//
+// Parameters:
+// - hash: the digest of the key.
+// - key: the key to set the value for.
+// - value: the value to set.
+//
+// Returns:
+// - V: the previous value of the key, or the zero value of V if the key was not in the dictionary.
+// - bool: true if the key was already in the dictionary, false if it was added.
+func (c *ConcurrentDict[K, V]) getAndSetHash(hash uint64, key K, value V) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.dict.getAndSetHash(hash, key, value)
+}
+
+// GetAndDelete deletes a key and returns the value it held, see Dict.GetAndDelete.
+//
+// Parameters:
+// - key: the key to delete.
+//
+// Returns:
+// - V: the value of the deleted key, or the zero value of V if the key was not in the dictionary.
+// - bool: true if the key was deleted, false if it was not in the dictionary.
+func (c *ConcurrentDict[K, V]) GetAndDelete(key K) (V, bool) {
+	return c.getAndDeleteHash(c.dict.hasher.Digest(key), key)
+}
+
+// getAndDeleteHash is GetAndDelete for a key whose digest is already computed.
+//
+// Parameters:
+// - hash: the digest of the key.
+// - key: the key to delete.
+//
+// Returns:
+// - V: the value of the deleted key, or the zero value of V if the key was not in the dictionary.
+// - bool: true if the key was deleted, false if it was not in the dictionary.
+func (c *ConcurrentDict[K, V]) getAndDeleteHash(hash uint64, key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.dict.getAndDeleteHash(hash, key)
+}
+
+// CompareAndSwap sets the value of a key to new only if its current value is equal to old,
+// see Dict.CompareAndSwap.
+//
+// Parameters:
+// - key: the key to update.
+// - old: the value the key is expected to hold.
+// - new: the value to set.
+//
+// Returns:
+// - bool: true if the value was swapped, false if the key is missing or holds another value.
+func (c *ConcurrentDict[K, V]) CompareAndSwap(key K, old, new V) bool {
+	return c.compareAndSwapHash(c.dict.hasher.Digest(key), key, old, new)
+}
+
+// compareAndSwapHash is CompareAndSwap for a key whose digest is already computed.
+//
+// Parameters:
+// - hash: the digest of the key.
+// - key: the key to update.
+// - old: the value the key is expected to hold.
+// - new: the value to set.
+//
+// Returns:
+// - bool: true if the value was swapped, false if the key is missing or holds another value.
+func (c *ConcurrentDict[K, V]) compareAndSwapHash(hash uint64, key K, old, new V) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.dict.compareAndSwapHash(hash, key, old, new)
+}
+
+// Upsert sets the value of a key to the result of fn, called with the current value of the key,
+// see Dict.Upsert. fn is called while holding the write lock, so it must not access the dictionary.
+//
+// Parameters:
+// - key: the key to update or insert.
+// - fn: the function computing the new value from the current one.
+//
+// Returns:
+// - V: the new value of the key.
+func (c *ConcurrentDict[K, V]) Upsert(key K, fn func(old V, exists bool) V) V {
+	return c.upsertHash(c.dict.hasher.Digest(key), key, fn)
+}
+
+// upsertHash is Upsert for a key whose digest is already computed.
+//
+// Parameters:
+// - hash: the digest of the key.
+// - key: the key to update or insert.
+// - fn: the function computing the new value from the current one.
+//
+// Returns:
+// - V: the new value of the key.
+func (c *ConcurrentDict[K, V]) upsertHash(hash uint64, key K, fn func(old V, exists bool) V) V {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.dict.upsertHash(hash, key, fn)
+}
+
+// GetAllItems retrieves all the key-value pairs of the dictionary.
+//
+// No parameters.
+// Returns a map holding a copy of all the key-value pairs.
+func (c *ConcurrentDict[K, V]) GetAllItems() map[K]V {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.dict.GetAllItems()
+}
+
+// Scan iterates incrementally over the elements of the dictionary, see Dict.Scan.
+// fn is called while holding the read lock, so it must not modify the dictionary.
+//
+// Parameters:
+// - cursor: the cursor returned by the previous call, or 0 to start a new iteration.
+// - fn: the function called for each element of the visited buckets.
+//
+// Returns:
+// - uint64: the cursor for the next call, or 0 if the iteration is complete.
+func (c *ConcurrentDict[K, V]) Scan(cursor uint64, fn func(key K, value V)) uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.dict.Scan(cursor, fn)
+}
+
+// Len returns the number of entries in the dictionary.
+//
+// No parameters.
+// Returns the number of entries.
+func (c *ConcurrentDict[K, V]) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.dict.Len()
+}
+
+// RehashMilliseconds performs incremental rehashing for at most the given amount of milliseconds
+// while holding the write lock, so that a RehashCron can drive it without an external lock.
+//
+// Parameters:
+// - ms: the time budget in milliseconds.
+//
+// Returns:
+// - int: the number of rehashing steps performed.
+func (c *ConcurrentDict[K, V]) RehashMilliseconds(ms int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.dict.RehashMilliseconds(ms)
+}
@@ -0,0 +1,44 @@
+package structure
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrentDict(t *testing.T) {
+	d := NewConcurrentSipHashDict()
+
+	assert.Equal(t, "", d.Get("key1"), "Unexpected value for nonexistent key")
+	assert.NoError(t, d.Set("key1", "value1"))
+	assert.Equal(t, "value1", d.Get("key1"), "Unexpected value for key1")
+	assert.Equal(t, map[string]string{"key1": "value1"}, d.GetAllItems(), "Unexpected items")
+
+	items := make(map[string]string)
+	cursor := uint64(0)
+	for {
+		cursor = d.Scan(cursor, func(key, value string) { items[key] = value })
+		if cursor == 0 {
+			break
+		}
+	}
+	assert.Equal(t, map[string]string{"key1": "value1"}, items, "Unexpected scanned items")
+
+	assert.NoError(t, d.Delete("key1"))
+	assert.ErrorIs(t, d.Delete("key1"), ErrKeyNotFound)
+}
+
+func TestConcurrentDictRehashCron(t *testing.T) {
+	d := NewConcurrentSipHashDict().(*ConcurrentDict[string, string])
+	for i := 0; i < 1000; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	d.dict.expand(1 << 16)
+
+	// The ConcurrentDict takes its own lock, so no external lock is needed
+	cron := NewRehashCron(time.Millisecond, 100, nil, d)
+	cron.Run()
+	assert.False(t, d.dict.isRehashing(), "Expected the cron to complete the rehashing")
+}
@@ -0,0 +1,205 @@
+package structure
+
+// SetIfAbsent sets the value of a key only if the key is not in the dictionary, like Redis SETNX.
+//
+// Parameters:
+// - key: the key to set the value for.
+// - value: the value to set.
+//
+// Returns:
+// - bool: true if the key was added, false if it was already in the dictionary.
+func (d *Dict[K, V]) SetIfAbsent(key K, value V) bool {
+	return d.setIfAbsentHash(d.hasher.Digest(key), key, value)
+}
+
+// setIfAbsentHash is SetIfAbsent for a key whose digest is already computed.
+//
+// Parameters:
+// - hash: the digest of the key.
+// - key: the key to set the value for.
+// - value: the value to set.
+//
+// Returns:
+// - bool: true if the key was added, false if it was already in the dictionary.
+func (d *Dict[K, V]) setIfAbsentHash(hash uint64, key K, value V) bool {
+	entry, hashTable, index := d.findPositionForHash(hash, key)
+	if entry != nil {
+		return false
+	}
+
+	d.insertAt(hashTable, index, key, value)
+	return true
+}
+
+// SetIfPresent sets the value of a key only if the key is already in the dictionary, like Redis SET XX.
+//
+// Parameters:
+// - key: the key to set the value for.
+// - value: the value to set.
+//
+// Returns:
+// - bool: true if the value was updated, false if the key is not in the dictionary.
+func (d *Dict[K, V]) SetIfPresent(key K, value V) bool {
+	return d.setIfPresentHash(d.hasher.Digest(key), key, value)
+}
+
+// setIfPresentHash is SetIfPresent for a key whose digest is already computed.
+//
+// Parameters:
+// - hash: the digest of the key.
+// - key: the key to set the value for.
+// - value: the value to set.
+//
+// Returns:
+// - bool: true if the value was updated, false if the key is not in the dictionary.
+func (d *Dict[K, V]) setIfPresentHash(hash uint64, key K, value V) bool {
+	entry := d.findEntry(hash, key)
+	if entry == nil {
+		return false
+	}
+
+	d.updateEntry(entry, value)
+	return true
+}
+
+// GetAndSet sets the value of a key and returns the value it replaced, like Redis GETSET.
+// The previous value is handed to the caller, so the ValDestructor of the DictType is not called on it.
+//
+// Parameters:
+// - key: the key to set the value for.
+// - value: the value to set.
+//
+// Returns:
+// - V: the previous value of the key, or the zero value of V if the key was not in the dictionary.
+// - bool: true if the key was already in the dictionary, false if it was added.
+func (d *Dict[K, V]) GetAndSet(key K, value V) (V, bool) {
+	return d.getAndSetHash(d.hasher.Digest(key), key, value)
+}
+
+// getAndSetHash is GetAndSet for a key whose digest is already computed.
+//
+// Parameters:
+// - hash: the digest of the key.
+// - key: the key to set the value for.
+// - value: the value to set.
+//
+// Returns:
+// - V: the previous value of the key, or the zero value of V if the key was not in the dictionary.
+// - bool: true if the key was already in the dictionary, false if it was added.
+func (d *Dict[K, V]) getAndSetHash(hash uint64, key K, value V) (V, bool) {
+	entry, hashTable, index := d.findPositionForHash(hash, key)
+	if entry == nil {
+		d.insertAt(hashTable, index, key, value)
+		var zero V
+		return zero, false
+	}
+
+	return d.swapValue(entry, value), true
+}
+
+// GetAndDelete deletes a key and returns the value it held, like Redis GETDEL.
+// The value is handed to the caller, so only the KeyDestructor of the DictType is called.
+//
+// Parameters:
+// - key: the key to delete.
+//
+// Returns:
+// - V: the value of the deleted key, or the zero value of V if the key was not in the dictionary.
+// - bool: true if the key was deleted, false if it was not in the dictionary.
+func (d *Dict[K, V]) GetAndDelete(key K) (V, bool) {
+	return d.getAndDeleteHash(d.hasher.Digest(key), key)
+}
+
+// getAndDeleteHash is GetAndDelete for a key whose digest is already computed.
+//
+// Parameters:
+// - hash: the digest of the key.
+// - key: the key to delete.
+//
+// Returns:
+// - V: the value of the deleted key, or the zero value of V if the key was not in the dictionary.
+// - bool: true if the key was deleted, false if it was not in the dictionary.
+func (d *Dict[K, V]) getAndDeleteHash(hash uint64, key K) (V, bool) {
+	entry := d.unlinkHash(hash, key)
+	if entry == nil {
+		var zero V
+		return zero, false
+	}
+
+	if !d.isKeyShared(entry) {
+		d.destroyKey(entry.key)
+	}
+	return entry.value, true
+}
+
+// CompareAndSwap sets the value of a key to new only if its current value is equal to old.
+// Like sync.Map.CompareAndSwap, the values are compared with ==, so the call panics if
+// the dynamic type of the values is not comparable.
+//
+// Parameters:
+// - key: the key to update.
+// - old: the value the key is expected to hold.
+// - new: the value to set.
+//
+// Returns:
+// - bool: true if the value was swapped, false if the key is missing or holds another value.
+func (d *Dict[K, V]) CompareAndSwap(key K, old, new V) bool {
+	return d.compareAndSwapHash(d.hasher.Digest(key), key, old, new)
+}
+
+// compareAndSwapHash is CompareAndSwap for a key whose digest is already computed.
+//
+// Parameters:
+// - hash: the digest of the key.
+// - key: the key to update.
+// - old: the value the key is expected to hold.
+// - new: the value to set.
+//
+// Returns:
+// - bool: true if the value was swapped, false if the key is missing or holds another value.
+func (d *Dict[K, V]) compareAndSwapHash(hash uint64, key K, old, new V) bool {
+	entry := d.findEntry(hash, key)
+	if entry == nil || any(entry.value) != any(old) {
+		return false
+	}
+
+	d.updateEntry(entry, new)
+	return true
+}
+
+// Upsert sets the value of a key to the result of fn, called with the current value of the key,
+// like a read-modify-write that hashes the key and walks its chain once. fn must not modify the dictionary.
+//
+// Parameters:
+// - key: the key to update or insert.
+// - fn: the function computing the new value from the current one; exists is false if the key is
+// not in the dictionary, in which case old is the zero value of V.
+//
+// Returns:
+// - V: the new value of the key.
+func (d *Dict[K, V]) Upsert(key K, fn func(old V, exists bool) V) V {
+	return d.upsertHash(d.hasher.Digest(key), key, fn)
+}
+
+// upsertHash is Upsert for a key whose digest is already computed.
+//
+// Parameters:
+// - hash: the digest of the key.
+// - key: the key to update or insert.
+// - fn: the function computing the new value from the current one.
+//
+// Returns:
+// - V: the new value of the key.
+func (d *Dict[K, V]) upsertHash(hash uint64, key K, fn func(old V, exists bool) V) V {
+	entry, hashTable, index := d.findPositionForHash(hash, key)
+	if entry == nil {
+		var zero V
+		value := fn(zero, false)
+		d.insertAt(hashTable, index, key, value)
+		return value
+	}
+
+	value := fn(entry.value, true)
+	d.updateEntry(entry, value)
+	return value
+}
@@ -0,0 +1,194 @@
+package structure
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/dmarro89/go-redis-hashtable/hashing"
+	"github.com/stretchr/testify/assert"
+)
+
+var conditionalDicts = map[string]func() IDict[string, string]{
+	"Dict":           NewSipHashDict,
+	"ConcurrentDict": NewConcurrentSipHashDict,
+	"ShardedDict":    func() IDict[string, string] { return NewShardedSipHashDict(4) },
+	"CowDict":        NewCowSipHashDict,
+	"BucketDict":     NewBucketSipHashDict,
+}
+
+func TestSetIfAbsent(t *testing.T) {
+	for name, newDict := range conditionalDicts {
+		t.Run(name, func(t *testing.T) {
+			d := newDict()
+
+			assert.True(t, d.SetIfAbsent("key1", "value1"), "Missing key should be added")
+			assert.False(t, d.SetIfAbsent("key1", "value2"), "Existing key should not be overwritten")
+			assert.Equal(t, "value1", d.Get("key1"), "Unexpected value for key1")
+			assert.Equal(t, 1, d.Len(), "Unexpected length")
+		})
+	}
+}
+
+func TestSetIfPresent(t *testing.T) {
+	for name, newDict := range conditionalDicts {
+		t.Run(name, func(t *testing.T) {
+			d := newDict()
+
+			assert.False(t, d.SetIfPresent("key1", "value1"), "Missing key should not be added")
+			_, found := d.Lookup("key1")
+			assert.False(t, found, "Missing key should still be missing")
+
+			assert.NoError(t, d.Set("key1", "value1"))
+			assert.True(t, d.SetIfPresent("key1", "value2"), "Existing key should be updated")
+			assert.Equal(t, "value2", d.Get("key1"), "Unexpected value for key1")
+		})
+	}
+}
+
+func TestGetAndSet(t *testing.T) {
+	for name, newDict := range conditionalDicts {
+		t.Run(name, func(t *testing.T) {
+			d := newDict()
+
+			old, found := d.GetAndSet("key1", "value1")
+			assert.False(t, found, "Missing key should not be found")
+			assert.Equal(t, "", old, "Unexpected previous value of a missing key")
+
+			old, found = d.GetAndSet("key1", "value2")
+			assert.True(t, found, "Existing key should be found")
+			assert.Equal(t, "value1", old, "Unexpected previous value of key1")
+			assert.Equal(t, "value2", d.Get("key1"), "Unexpected value for key1")
+		})
+	}
+}
+
+func TestGetAndDelete(t *testing.T) {
+	for name, newDict := range conditionalDicts {
+		t.Run(name, func(t *testing.T) {
+			d := newDict()
+
+			_, found := d.GetAndDelete("key1")
+			assert.False(t, found, "Missing key should not be found")
+
+			assert.NoError(t, d.Set("key1", "value1"))
+			value, found := d.GetAndDelete("key1")
+			assert.True(t, found, "Existing key should be found")
+			assert.Equal(t, "value1", value, "Unexpected deleted value")
+			assert.Equal(t, 0, d.Len(), "Unexpected length after delete")
+		})
+	}
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	for name, newDict := range conditionalDicts {
+		t.Run(name, func(t *testing.T) {
+			d := newDict()
+
+			assert.False(t, d.CompareAndSwap("key1", "", "value1"), "Missing key should not be swapped")
+
+			assert.NoError(t, d.Set("key1", "value1"))
+			assert.False(t, d.CompareAndSwap("key1", "other", "value2"), "Mismatching value should not be swapped")
+			assert.Equal(t, "value1", d.Get("key1"), "Unexpected value for key1")
+			assert.True(t, d.CompareAndSwap("key1", "value1", "value2"), "Matching value should be swapped")
+			assert.Equal(t, "value2", d.Get("key1"), "Unexpected value for key1")
+		})
+	}
+}
+
+func TestConditionalWhileRehashing(t *testing.T) {
+	for name, newDict := range conditionalDicts {
+		t.Run(name, func(t *testing.T) {
+			d := newDict()
+
+			// Inserting many keys makes the operations run across several rehashings
+			for i := 0; i < 1000; i++ {
+				assert.True(t, d.SetIfAbsent(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i)))
+			}
+			for i := 0; i < 1000; i += 2 {
+				_, found := d.GetAndSet(fmt.Sprintf("key%d", i), "updated")
+				assert.True(t, found, "key%d should be found", i)
+			}
+			for i := 1; i < 1000; i += 2 {
+				_, found := d.GetAndDelete(fmt.Sprintf("key%d", i))
+				assert.True(t, found, "key%d should be found", i)
+			}
+
+			assert.Equal(t, 500, d.Len(), "Unexpected length")
+			for i := 0; i < 1000; i++ {
+				value, found := d.Lookup(fmt.Sprintf("key%d", i))
+				assert.Equal(t, i%2 == 0, found, "Unexpected presence of key%d", i)
+				if found {
+					assert.Equal(t, "updated", value, "Unexpected value for key%d", i)
+				}
+			}
+		})
+	}
+}
+
+func TestConditionalSnapshot(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	for i := 0; i < 100; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	s := d.Snapshot()
+
+	assert.True(t, d.SetIfPresent("key0", "updated"))
+	assert.True(t, d.CompareAndSwap("key1", "value1", "updated"))
+	d.GetAndSet("key2", "updated")
+	d.GetAndDelete("key3")
+	assert.True(t, d.SetIfAbsent("new", "value"))
+
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("key%d", i)
+		assert.Equal(t, fmt.Sprintf("value%d", i), s.Get(key), "Snapshot should not see the change of %s", key)
+	}
+	_, found := s.Lookup("new")
+	assert.False(t, found, "Snapshot should not see keys added after it was taken")
+	assert.Equal(t, "updated", d.Get("key2"), "Unexpected value for key2")
+}
+
+func TestCompareAndSwapNotComparable(t *testing.T) {
+	d := NewDict[string, any](hashing.NewSip24Hasher())
+	d.Set("key1", []int{1})
+
+	assert.Panics(t, func() { d.CompareAndSwap("key1", []int{1}, []int{2}) }, "Comparing slices should panic")
+}
+
+func TestUpsert(t *testing.T) {
+	for name, newDict := range conditionalDicts {
+		t.Run(name, func(t *testing.T) {
+			d := newDict()
+			appendValue := func(old string, exists bool) string {
+				if !exists {
+					return "a"
+				}
+				return old + "a"
+			}
+
+			assert.Equal(t, "a", d.Upsert("key1", appendValue), "Missing key should be inserted")
+			assert.Equal(t, "aa", d.Upsert("key1", appendValue), "Existing key should be updated")
+			assert.Equal(t, "aa", d.Get("key1"), "Unexpected value for key1")
+
+			for i := 0; i < 1000; i++ {
+				d.Upsert(fmt.Sprintf("key%d", i%100), appendValue)
+			}
+			assert.Equal(t, 100, d.Len(), "Unexpected length")
+			assert.Equal(t, "aaaaaaaaaaaa", d.Get("key1"), "Unexpected value for key1")
+			assert.Equal(t, "aaaaaaaaaa", d.Get("key99"), "Unexpected value for key99")
+		})
+	}
+}
+
+func TestUpsertSnapshot(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	d.Set("key1", "value1")
+	s := d.Snapshot()
+
+	d.Upsert("key1", func(old string, exists bool) string { return old + "-updated" })
+	d.Upsert("key2", func(old string, exists bool) string { return "value2" })
+
+	assert.Equal(t, "value1-updated", d.Get("key1"), "Unexpected value for key1")
+	assert.Equal(t, "value1", s.Get("key1"), "Snapshot should not see the update of key1")
+	_, found := s.Lookup("key2")
+	assert.False(t, found, "Snapshot should not see keys added after it was taken")
+}
@@ -0,0 +1,699 @@
+package structure
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/dmarro89/go-redis-hashtable/hashing"
+)
+
+// cowHashTable is the HashTable of a CowDict: its buckets are atomic pointers,
+// so that readers can load the head of a chain while a writer publishes a new one.
+type cowHashTable[K comparable, V any] struct {
+	table    []atomic.Pointer[DictEntry[K, V]]
+	size     int64
+	sizemask uint64
+	// used is only accessed by the writers, under the lock of the CowDict
+	used int64
+}
+
+// newCowHashTable creates a new cowHashTable with the specified size.
+//
+// Parameters:
+// - size: the size of the cowHashTable.
+//
+// Returns:
+// - *cowHashTable: a pointer to the newly created cowHashTable.
+func newCowHashTable[K comparable, V any](size int64) *cowHashTable[K, V] {
+	var sizemask uint64
+	if size > 0 {
+		sizemask = uint64(size - 1)
+	}
+
+	return &cowHashTable[K, V]{
+		table:    make([]atomic.Pointer[DictEntry[K, V]], size),
+		size:     size,
+		sizemask: sizemask,
+	}
+}
+
+// CowDict is a dictionary safe for concurrent use whose readers never block.
+//
+// The pair of hash tables is published through an atomic pointer and the DictEntry
+// chains are never modified once published: writers, serialized by a mutex, build a
+// copy of the part of the chain they change and publish its new head atomically
+// (copy-on-write). The incremental rehashing migrates a bucket by publishing copies
+// of its entries in the rehashing table before clearing it in the main table, so
+// a reader probing the main table first and the rehashing table second finds an
+// entry present for the whole lookup, as long as the pair of hash tables does not
+// change. A reader missing a key loads the pair again and retries if it changed,
+// since a pair loaded before an expansion does not hold the table the entries
+// are migrated to.
+type CowDict[K comparable, V any] struct {
+	mu        sync.Mutex
+	tables    atomic.Pointer[[2]*cowHashTable[K, V]]
+	length    atomic.Int64
+	rehashidx int
+	hasher    hashing.IHasher[K]
+}
+
+// NewCowDict returns a new instance of CowDict whose keys are hashed by the given hasher.
+//
+// Parameters:
+// - hasher: the hashing.IHasher used to compute the bucket of each key.
+//
+// Returns:
+// - IDict: a pointer to the newly created CowDict.
+func NewCowDict[K comparable, V any](hasher hashing.IHasher[K]) IDict[K, V] {
+	d := &CowDict[K, V]{
+		rehashidx: -1,
+		hasher:    hasher,
+	}
+	d.tables.Store(&[2]*cowHashTable[K, V]{newCowHashTable[K, V](0), newCowHashTable[K, V](0)})
+	return d
+}
+
+// NewCowSipHashDict returns a new instance of a string/string CowDict hashed with SipHash-2-4.
+//
+// The function does not take any parameters.
+// It returns a pointer to CowDict.
+func NewCowSipHashDict() IDict[string, string] {
+	return NewCowDict[string, string](hashing.NewSip24Hasher())
+}
+
+// isRehashing checks if the rehash index of the CowDict is not equal to -1.
+// It must be called by the writers only.
+func (d *CowDict[K, V]) isRehashing() bool {
+	return d.rehashidx != -1
+}
+
+// expand resizes the dictionary to a new size if necessary, see Dict.expand.
+//
+// newSize: the new size to resize the dictionary to.
+// The function does not return anything.
+func (d *CowDict[K, V]) expand(newSize int64) {
+	tables := d.tables.Load()
+	if d.isRehashing() || tables[0].used > newSize {
+		return
+	}
+
+	nextSize := nextPower(newSize)
+	if nextSize == tables[0].size {
+		return
+	}
+
+	newHashTable := newCowHashTable[K, V](nextSize)
+	if tables[0].size == 0 {
+		d.tables.Store(&[2]*cowHashTable[K, V]{newHashTable, tables[1]})
+		return
+	}
+
+	d.tables.Store(&[2]*cowHashTable[K, V]{tables[0], newHashTable})
+	d.rehashidx = 0
+}
+
+// expandIfNeeded checks if the dictionary needs to be expanded and performs the expansion if necessary.
+//
+// No parameters.
+// No return values.
+func (d *CowDict[K, V]) expandIfNeeded() {
+	if d.isRehashing() {
+		return
+	}
+
+	main := d.tables.Load()[0]
+	if main.size == 0 {
+		d.expand(INITIAL_SIZE)
+	} else if main.used >= main.size {
+		d.expand(main.used * 2)
+	}
+}
+
+// shrinkIfNeeded shrinks the dictionary if it is filled for less than 1/HASHTABLE_MIN_FILL.
+//
+// No parameters.
+// No return values.
+func (d *CowDict[K, V]) shrinkIfNeeded() {
+	if d.isRehashing() {
+		return
+	}
+
+	main := d.tables.Load()[0]
+	if main.size > INITIAL_SIZE && main.used*HASHTABLE_MIN_FILL <= main.size {
+		d.expand(main.used)
+	}
+}
+
+// rehash migrates n buckets from the main table to the rehashing table.
+// The entries of a bucket are published in the rehashing table before the bucket is cleared.
+//
+// n is the number of buckets to migrate.
+// Returns false if the rehashing is not in progress anymore.
+// Returns true if there are still entries to migrate.
+func (d *CowDict[K, V]) rehash(n int) bool {
+	if !d.isRehashing() {
+		return false
+	}
+
+	tables := d.tables.Load()
+	main, rehashing := tables[0], tables[1]
+	emptyVisits := n * 10
+
+	for n > 0 && main.used != 0 {
+		n--
+
+		for main.table[d.rehashidx].Load() == nil {
+			d.rehashidx++
+			emptyVisits--
+			if emptyVisits == 0 {
+				return true
+			}
+		}
+
+		// The entries are copied, since their next pointers are immutable
+		for entry := main.table[d.rehashidx].Load(); entry != nil; entry = entry.next {
+			idx := d.hasher.Digest(entry.key) & rehashing.sizemask
+			moved := NewDictEntry[K, V](entry.key, entry.value)
+			moved.next = rehashing.table[idx].Load()
+			rehashing.table[idx].Store(moved)
+			main.used--
+			rehashing.used++
+		}
+
+		main.table[d.rehashidx].Store(nil)
+		d.rehashidx++
+	}
+
+	if main.used == 0 {
+		d.tables.Store(&[2]*cowHashTable[K, V]{rehashing, newCowHashTable[K, V](0)})
+		d.rehashidx = -1
+		// Shrink again after a shrinking rehash, see Dict.rehash
+		if rehashing.size < main.size {
+			d.shrinkIfNeeded()
+		}
+		return d.isRehashing()
+	}
+
+	return true
+}
+
+// rewriteChain returns a copy of the chain starting at entry in which the entry with the given key
+// is replaced by replacement, or removed if replacement is nil. The entries after the one
+// replaced are shared with the original chain, which is left untouched.
+//
+// Parameters:
+// - entry: the head of the chain.
+// - key: the key of the entry to replace.
+// - replacement: the entry replacing it, or nil to remove it.
+//
+// Returns:
+// - *DictEntry: the head of the new chain.
+// - bool: false if the key is not in the chain.
+func rewriteChain[K comparable, V any](entry *DictEntry[K, V], key K, replacement *DictEntry[K, V]) (*DictEntry[K, V], bool) {
+	if entry == nil {
+		return nil, false
+	}
+
+	if entry.key == key {
+		if replacement == nil {
+			return entry.next, true
+		}
+		replacement.next = entry.next
+		return replacement, true
+	}
+
+	next, found := rewriteChain(entry.next, key, replacement)
+	if !found {
+		return entry, false
+	}
+
+	entryCopy := NewDictEntry[K, V](entry.key, entry.value)
+	entryCopy.next = next
+	return entryCopy, true
+}
+
+// Get returns the value associated with the given key in the dictionary, without taking any lock.
+//
+// Parameters:
+// - key: the key to look up in the dictionary.
+//
+// Return:
+// - V: the value associated with the key, or the zero value of V if the key is not found.
+func (d *CowDict[K, V]) Get(key K) V {
+	value, _ := d.Lookup(key)
+	return value
+}
+
+// Lookup returns the value associated with the given key and whether it was found, without taking any lock.
+//
+// Parameters:
+// - key: the key to look up in the dictionary.
+//
+// Return:
+// - V: the value associated with the key, or the zero value of V if the key is not found.
+// - bool: true if the key is in the dictionary, false otherwise.
+func (d *CowDict[K, V]) Lookup(key K) (V, bool) {
+	return d.lookup(d.tables.Load(), d.hasher.Digest(key), key)
+}
+
+// lookup searches the given key in a pair of hash tables loaded by the caller.
+// On a miss, the pair is loaded again and the search is retried if it changed.
+//
+// Parameters:
+// - tables: the pair of hash tables to search first.
+// - hash: the digest of the key.
+// - key: the key to look up.
+//
+// Returns:
+// - V: the value associated with the key, or the zero value of V if the key is not found.
+// - bool: true if the key is in the dictionary, false otherwise.
+func (d *CowDict[K, V]) lookup(tables *[2]*cowHashTable[K, V], hash uint64, key K) (V, bool) {
+	for {
+		for _, hashTable := range tables {
+			if hashTable.size == 0 {
+				continue
+			}
+
+			for entry := hashTable.table[hash&hashTable.sizemask].Load(); entry != nil; entry = entry.next {
+				if entry.key == key {
+					return entry.value, true
+				}
+			}
+		}
+
+		current := d.tables.Load()
+		if current == tables {
+			var zero V
+			return zero, false
+		}
+		tables = current
+	}
+}
+
+// find returns the entry of the given key and the bucket holding it.
+// It must be called by the writers only.
+//
+// Parameters:
+// - hash: the digest of the key.
+// - key: the key to search for.
+//
+// Returns:
+// - *cowHashTable: the hash table holding the entry, or nil if not found.
+// - *atomic.Pointer: the bucket holding the entry, or nil if not found.
+// - *DictEntry: the entry of the key, or nil if not found.
+func (d *CowDict[K, V]) find(hash uint64, key K) (*cowHashTable[K, V], *atomic.Pointer[DictEntry[K, V]], *DictEntry[K, V]) {
+	for i, hashTable := range d.tables.Load() {
+		if hashTable.size == 0 || (i == 1 && !d.isRehashing()) {
+			continue
+		}
+
+		bucket := &hashTable.table[hash&hashTable.sizemask]
+		for entry := bucket.Load(); entry != nil; entry = entry.next {
+			if entry.key == key {
+				return hashTable, bucket, entry
+			}
+		}
+	}
+
+	return nil, nil, nil
+}
+
+// insert publishes a new entry at the head of its bucket, without checking whether the key
+// is already in the dictionary. While rehashing, the entry goes to the rehashing table.
+//
+// Parameters:
+// - hash: the digest of the key.
+// - key: the key of the new entry, which must not be in the dictionary.
+// - value: the value of the new entry.
+//
+// No return values.
+func (d *CowDict[K, V]) insert(hash uint64, key K, value V) {
+	tables := d.tables.Load()
+	hashTable := tables[0]
+	if d.isRehashing() {
+		hashTable = tables[1]
+	}
+
+	bucket := &hashTable.table[hash&hashTable.sizemask]
+	entry := NewDictEntry[K, V](key, value)
+	entry.next = bucket.Load()
+	bucket.Store(entry)
+	hashTable.used++
+	d.length.Add(1)
+}
+
+// replace publishes a copy of the chain of the bucket in which the entry of the given key
+// holds the new value.
+//
+// Parameters:
+// - bucket: the bucket holding the entry, as returned by find.
+// - key: the key of the entry.
+// - value: the new value.
+//
+// No return values.
+func (d *CowDict[K, V]) replace(bucket *atomic.Pointer[DictEntry[K, V]], key K, value V) {
+	head, _ := rewriteChain(bucket.Load(), key, NewDictEntry[K, V](key, value))
+	bucket.Store(head)
+}
+
+// remove publishes a copy of the chain of the bucket without the entry of the given key.
+//
+// Parameters:
+// - hashTable: the hash table holding the entry, as returned by find.
+// - bucket: the bucket holding the entry, as returned by find.
+// - key: the key of the entry.
+//
+// No return values.
+func (d *CowDict[K, V]) remove(hashTable *cowHashTable[K, V], bucket *atomic.Pointer[DictEntry[K, V]], key K) {
+	head, _ := rewriteChain(bucket.Load(), key, nil)
+	bucket.Store(head)
+	hashTable.used--
+	d.length.Add(-1)
+	d.shrinkIfNeeded()
+}
+
+// Set sets the value of a key in the dictionary.
+// An existing entry is replaced by a new one, publishing a copy of its chain.
+//
+// Parameters:
+//   - key: the key to set the value for.
+//   - value: the value to set.
+//
+// Returns:
+//   - error: always nil.
+func (d *CowDict[K, V]) Set(key K, value V) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.expandIfNeeded()
+	d.rehash(1)
+
+	hash := d.hasher.Digest(key)
+	if _, bucket, entry := d.find(hash, key); entry != nil {
+		d.replace(bucket, key, value)
+		return nil
+	}
+
+	d.insert(hash, key, value)
+	return nil
+}
+
+// Delete deletes an entry from the dictionary, publishing a copy of its chain without it.
+//
+// Parameters:
+// - key: the key of the entry to be deleted.
+//
+// Returns:
+// - error: ErrKeyNotFound if the entry is not found.
+func (d *CowDict[K, V]) Delete(key K) error {
+	if _, found := d.GetAndDelete(key); !found {
+		return ErrKeyNotFound
+	}
+	return nil
+}
+
+// SetIfAbsent sets the value of a key only if the key is not in the dictionary, see Dict.SetIfAbsent.
+//
+// Parameters:
+// - key: the key to set the value for.
+// - value: the value to set.
+//
+// Returns:
+// - bool: true if the key was added, false if it was already in the dictionary.
+func (d *CowDict[K, V]) SetIfAbsent(key K, value V) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.expandIfNeeded()
+	d.rehash(1)
+
+	hash := d.hasher.Digest(key)
+	if _, _, entry := d.find(hash, key); entry != nil {
+		return false
+	}
+
+	d.insert(hash, key, value)
+	return true
+}
+
+// SetIfPresent sets the value of a key only if the key is already in the dictionary, see Dict.SetIfPresent.
+//
+// Parameters:
+// - key: the key to set the value for.
+// - value: the value to set.
+//
+// Returns:
+// - bool: true if the value was updated, false if the key is not in the dictionary.
+func (d *CowDict[K, V]) SetIfPresent(key K, value V) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.rehash(1)
+
+	_, bucket, entry := d.find(d.hasher.Digest(key), key)
+	if entry == nil {
+		return false
+	}
+
+	d.replace(bucket, key, value)
+	return true
+}
+
+// GetAndSet sets the value of a key and returns the value it replaced, see Dict.GetAndSet.
+//
+// Parameters:
+// - key: the key to set the value for.
+// - value: the value to set.
+//
+// Returns:
+// - V: the previous value of the key, or the zero value of V if the key was not in the dictionary.
+// - bool: true if the key was already in the dictionary, false if it was added.
+func (d *CowDict[K, V]) GetAndSet(key K, value V) (V, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.expandIfNeeded()
+	d.rehash(1)
+
+	hash := d.hasher.Digest(key)
+	if _, bucket, entry := d.find(hash, key); entry != nil {
+		d.replace(bucket, key, value)
+		return entry.value, true
+	}
+
+	d.insert(hash, key, value)
+	var zero V
+	return zero, false
+}
+
+// GetAndDelete deletes a key and returns the value it held, see Dict.GetAndDelete.
+//
+// Parameters:
+// - key: the key to delete.
+//
+// Returns:
+// - V: the value of the deleted key, or the zero value of V if the key was not in the dictionary.
+// - bool: true if the key was deleted, false if it was not in the dictionary.
+func (d *CowDict[K, V]) GetAndDelete(key K) (V, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.rehash(1)
+
+	hashTable, bucket, entry := d.find(d.hasher.Digest(key), key)
+	if entry == nil {
+		var zero V
+		return zero, false
+	}
+
+	d.remove(hashTable, bucket, key)
+	return entry.value, true
+}
+
+// CompareAndSwap sets the value of a key to new only if its current value is equal to old,
+// see Dict.CompareAndSwap.
+//
+// Parameters:
+// - key: the key to update.
+// - old: the value the key is expected to hold.
+// - new: the value to set.
+//
+// Returns:
+// - bool: true if the value was swapped, false if the key is missing or holds another value.
+func (d *CowDict[K, V]) CompareAndSwap(key K, old, new V) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.rehash(1)
+
+	_, bucket, entry := d.find(d.hasher.Digest(key), key)
+	if entry == nil || any(entry.value) != any(old) {
+		return false
+	}
+
+	d.replace(bucket, key, new)
+	return true
+}
+
+// Upsert sets the value of a key to the result of fn, called with the current value of the key,
+// see Dict.Upsert. fn is called while holding the writers lock, so it must not access the dictionary.
+//
+// Parameters:
+// - key: the key to update or insert.
+// - fn: the function computing the new value from the current one.
+//
+// Returns:
+// - V: the new value of the key.
+func (d *CowDict[K, V]) Upsert(key K, fn func(old V, exists bool) V) V {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.expandIfNeeded()
+	d.rehash(1)
+
+	hash := d.hasher.Digest(key)
+	if _, bucket, entry := d.find(hash, key); entry != nil {
+		value := fn(entry.value, true)
+		d.replace(bucket, key, value)
+		return value
+	}
+
+	var zero V
+	value := fn(zero, false)
+	d.insert(hash, key, value)
+	return value
+}
+
+// GetAllItems retrieves all the key-value pairs of the dictionary, without taking any lock.
+// Every entry present for the whole call is returned.
+//
+// No parameters.
+// Returns a map holding a copy of all the key-value pairs.
+func (d *CowDict[K, V]) GetAllItems() map[K]V {
+	return d.getAllItems(d.tables.Load())
+}
+
+// getAllItems collects the entries of a pair of hash tables loaded by the caller.
+// The walk is repeated with the current pair until the pair does not change during a walk.
+//
+// Parameters:
+// - tables: the pair of hash tables to walk first.
+//
+// Returns:
+// - map[K]V: a copy of all the key-value pairs.
+func (d *CowDict[K, V]) getAllItems(tables *[2]*cowHashTable[K, V]) map[K]V {
+	for {
+		items := make(map[K]V)
+		for _, hashTable := range tables {
+			for i := range hashTable.table {
+				for entry := hashTable.table[i].Load(); entry != nil; entry = entry.next {
+					items[entry.key] = entry.value
+				}
+			}
+		}
+
+		current := d.tables.Load()
+		if current == tables {
+			return items
+		}
+		tables = current
+	}
+}
+
+// Scan iterates incrementally over the elements of the dictionary without taking any lock,
+// with the same guarantees of Dict.Scan.
+//
+// Parameters:
+// - cursor: the cursor returned by the previous call, or 0 to start a new iteration.
+// - fn: the function called for each element of the visited buckets.
+//
+// Returns:
+// - uint64: the cursor for the next call, or 0 if the iteration is complete.
+func (d *CowDict[K, V]) Scan(cursor uint64, fn func(key K, value V)) uint64 {
+	return d.scan(d.tables.Load(), cursor, fn)
+}
+
+// scan visits the buckets of the given cursor in a pair of hash tables loaded by the caller.
+// The visit is repeated with the current pair until the pair does not change during a visit,
+// so fn may be called more than once for the same element.
+//
+// Parameters:
+// - tables: the pair of hash tables to visit first.
+// - cursor: the cursor of the buckets to visit.
+// - fn: the function called for each element of the visited buckets.
+//
+// Returns:
+// - uint64: the cursor for the next call, or 0 if the iteration is complete.
+func (d *CowDict[K, V]) scan(tables *[2]*cowHashTable[K, V], cursor uint64, fn func(key K, value V)) uint64 {
+	for {
+		next := scanTables(tables, cursor, fn)
+
+		current := d.tables.Load()
+		if current == tables {
+			if d.length.Load() == 0 {
+				return 0
+			}
+			return next
+		}
+		tables = current
+	}
+}
+
+// scanTables visits the buckets of the given cursor in a pair of cowHashTables, see Dict.Scan.
+//
+// Parameters:
+// - tables: the pair of hash tables to visit.
+// - cursor: the cursor of the buckets to visit.
+// - fn: the function called for each element of the visited buckets.
+//
+// Returns:
+// - uint64: the cursor for the next call, or 0 if the iteration is complete.
+func scanTables[K comparable, V any](tables *[2]*cowHashTable[K, V], cursor uint64, fn func(key K, value V)) uint64 {
+	if tables[0].size == 0 {
+		return 0
+	}
+
+	// The rehashing table is allocated only while rehashing
+	if tables[1].size == 0 {
+		scanBucket(tables[0].table[cursor&tables[0].sizemask].Load(), fn)
+		return nextCursor(cursor, tables[0].sizemask)
+	}
+
+	small, large := tables[0], tables[1]
+	if small.size > large.size {
+		small, large = large, small
+	}
+
+	scanBucket(small.table[cursor&small.sizemask].Load(), fn)
+	for {
+		scanBucket(large.table[cursor&large.sizemask].Load(), fn)
+		cursor = nextCursor(cursor, large.sizemask)
+		if cursor&(small.sizemask^large.sizemask) == 0 {
+			break
+		}
+	}
+
+	return cursor
+}
+
+// Len returns the number of entries in the dictionary, without taking any lock.
+//
+// No parameters.
+// Returns the number of entries.
+func (d *CowDict[K, V]) Len() int {
+	return int(d.length.Load())
+}
+
+// RehashMilliseconds performs incremental rehashing in batches of 100 steps
+// for at most the given amount of milliseconds, holding the writers lock.
+//
+// Parameters:
+// - ms: the time budget in milliseconds.
+//
+// Returns:
+// - int: the number of rehashing steps performed.
+func (d *CowDict[K, V]) RehashMilliseconds(ms int) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return rehashMilliseconds(ms, d.isRehashing, func() { d.rehash(1) })
+}
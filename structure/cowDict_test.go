@@ -0,0 +1,205 @@
+package structure
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dmarro89/go-redis-hashtable/hashing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCowDict(t *testing.T) {
+	d := NewCowSipHashDict()
+
+	assert.Equal(t, "", d.Get("key1"), "Unexpected value for nonexistent key")
+	assert.NoError(t, d.Set("key1", "value1"))
+	assert.Equal(t, "value1", d.Get("key1"), "Unexpected value for key1")
+	assert.NoError(t, d.Set("key1", "updated"))
+	assert.Equal(t, "updated", d.Get("key1"), "Unexpected value for key1 after update")
+	assert.Equal(t, 1, d.Len(), "Unexpected length after update")
+
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i)))
+	}
+	assert.Equal(t, 1000, d.Len(), "Unexpected length")
+	for i := 0; i < 1000; i++ {
+		assert.Equal(t, fmt.Sprintf("value%d", i), d.Get(fmt.Sprintf("key%d", i)), "Unexpected value for key%d", i)
+	}
+
+	items := make(map[string]string)
+	cursor := uint64(0)
+	for {
+		cursor = d.Scan(cursor, func(key, value string) { items[key] = value })
+		if cursor == 0 {
+			break
+		}
+	}
+	assert.Equal(t, d.GetAllItems(), items, "Scan should return every element of the dictionary")
+	assert.Len(t, items, 1000, "Unexpected number of items")
+
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, d.Delete(fmt.Sprintf("key%d", i)))
+	}
+	assert.ErrorIs(t, d.Delete("key0"), ErrKeyNotFound)
+	assert.Equal(t, 0, d.Len(), "Unexpected length after deleting every key")
+	assert.Empty(t, d.GetAllItems(), "Unexpected items after deleting every key")
+}
+
+func TestCowDictShrink(t *testing.T) {
+	d := NewCowSipHashDict().(*CowDict[string, string])
+	for i := 0; i < 1000; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	for i := 0; i < 990; i++ {
+		d.Delete(fmt.Sprintf("key%d", i))
+	}
+	d.RehashMilliseconds(1000)
+
+	assert.False(t, d.isRehashing(), "Expected the rehashing to be complete")
+	assert.True(t, d.tables.Load()[0].size < 1024, "Expected the table to shrink after mass deletes")
+	for i := 990; i < 1000; i++ {
+		assert.Equal(t, fmt.Sprintf("value%d", i), d.Get(fmt.Sprintf("key%d", i)), "Unexpected value for key%d", i)
+	}
+}
+
+func TestCowDictShrink_DeletesDuringShrink(t *testing.T) {
+	d := NewCowSipHashDict().(*CowDict[string, string])
+	for i := 0; i < 1000; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	d.RehashMilliseconds(1000)
+
+	// The table shrinks to 128 buckets when the fill falls under 1/8
+	for i := 0; i < 872; i++ {
+		assert.NoError(t, d.Delete(fmt.Sprintf("key%d", i)))
+	}
+	assert.True(t, d.isRehashing(), "Expected the table to start shrinking")
+	assert.Equal(t, int64(128), d.tables.Load()[1].size, "Unexpected size of the shrunk table")
+
+	// The deletes performed while shrinking bring the fill of the shrunk table under 1/8,
+	// without any rehashing step
+	for i := 872; i < 990; i++ {
+		key := fmt.Sprintf("key%d", i)
+		hashTable, bucket, entry := d.find(d.hasher.Digest(key), key)
+		assert.NotNil(t, entry, "Key %s should be found", key)
+		d.remove(hashTable, bucket, key)
+	}
+
+	d.rehash(1024)
+	assert.Equal(t, int64(128), d.tables.Load()[0].size, "Expected the first shrink to be complete")
+	assert.True(t, d.isRehashing(), "Expected a second shrink to be scheduled")
+	assert.Equal(t, int64(16), d.tables.Load()[1].size, "Unexpected size of the table after the second shrink")
+
+	d.RehashMilliseconds(1000)
+	assert.Equal(t, int64(16), d.tables.Load()[0].size, "Unexpected size after the second shrink")
+	for i := 990; i < 1000; i++ {
+		assert.Equal(t, fmt.Sprintf("value%d", i), d.Get(fmt.Sprintf("key%d", i)), "Unexpected value for key%d", i)
+	}
+}
+
+func TestCowDictChainsAreImmutable(t *testing.T) {
+	d := NewCowSipHashDict().(*CowDict[string, string])
+	for i := 0; i < 3; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+
+	// Keep the chains as a reader would have loaded them
+	tables := d.tables.Load()
+	var heads []*DictEntry[string, string]
+	var values []string
+	for i := range tables[0].table {
+		for entry := tables[0].table[i].Load(); entry != nil; entry = entry.next {
+			heads = append(heads, entry)
+			values = append(values, entry.value)
+		}
+	}
+
+	d.Set("key0", "updated")
+	d.Delete("key1")
+
+	for i, entry := range heads {
+		assert.Equal(t, values[i], entry.value, "Published entries should never be modified")
+	}
+}
+
+func TestCowDictReadersRetryAfterExpand(t *testing.T) {
+	// Keys 0 to 3 fill the 4 buckets of the initial table, one each
+	hasher := hashing.HasherFunc[int](func(key int) uint64 { return uint64(key) })
+	d := NewCowDict[int, int](hasher).(*CowDict[int, int])
+	for i := 0; i < 4; i++ {
+		d.Set(i, i)
+	}
+
+	// A reader loads the pair of tables, then the writers expand the table
+	// and migrate the buckets of keys 0 and 1 before the reader probes them
+	stale := d.tables.Load()
+	d.Set(4, 4)
+	d.Set(5, 5)
+	assert.True(t, d.isRehashing(), "Expected the dictionary to be rehashing")
+	assert.Nil(t, stale[0].table[0].Load(), "Expected the bucket of key 0 to be migrated")
+
+	for i := 0; i < 4; i++ {
+		value, found := d.lookup(stale, hasher.Digest(i), i)
+		assert.True(t, found, "Key %d, present for the whole lookup, should be found", i)
+		assert.Equal(t, i, value, "Unexpected value for key %d", i)
+	}
+	_, found := d.lookup(stale, hasher.Digest(100), 100)
+	assert.False(t, found, "A missing key should not be found")
+
+	items := d.getAllItems(stale)
+	for i := 0; i < 4; i++ {
+		assert.Contains(t, items, i, "GetAllItems should return key %d, present for the whole call", i)
+	}
+
+	scanned := make(map[int]bool)
+	cursor := d.scan(stale, 0, func(key, value int) { scanned[key] = true })
+	for cursor != 0 {
+		cursor = d.Scan(cursor, func(key, value int) { scanned[key] = true })
+	}
+	for i := 0; i < 4; i++ {
+		assert.True(t, scanned[i], "Scan should return key %d, present for the whole scan", i)
+	}
+}
+
+func TestCowDictGetDoesNotBlock(t *testing.T) {
+	d := NewCowSipHashDict().(*CowDict[string, string])
+	d.Set("key1", "value1")
+
+	// Simulate a writer holding the lock
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	done := make(chan string)
+	go func() { done <- d.Get("key1") }()
+
+	select {
+	case value := <-done:
+		assert.Equal(t, "value1", value, "Unexpected value for key1")
+	case <-time.After(time.Second):
+		t.Fatal("Get should not block behind a writer")
+	}
+}
+
+func TestRewriteChain(t *testing.T) {
+	first := NewDictEntry[string, string]("key1", "value1")
+	second := NewDictEntry[string, string]("key2", "value2")
+	third := NewDictEntry[string, string]("key3", "value3")
+	first.next, second.next = second, third
+
+	head, found := rewriteChain(first, "missing", nil)
+	assert.False(t, found, "Unexpected key found")
+	assert.Equal(t, first, head, "The chain should be unchanged")
+
+	head, found = rewriteChain(first, "key2", nil)
+	assert.True(t, found, "Expected key2 to be found")
+	assert.Equal(t, "key1", head.key)
+	assert.NotSame(t, first, head, "The entries before the removed one should be copied")
+	assert.Same(t, third, head.next, "The entries after the removed one should be shared")
+	assert.Same(t, second, first.next, "The original chain should be untouched")
+
+	head, found = rewriteChain(first, "key1", NewDictEntry[string, string]("key1", "updated"))
+	assert.True(t, found, "Expected key1 to be found")
+	assert.Equal(t, "updated", head.value)
+	assert.Same(t, second, head.next, "The entries after the replaced one should be shared")
+}
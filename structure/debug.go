@@ -0,0 +1,20 @@
+//go:build dictdebug
+
+package structure
+
+import "math/bits"
+
+// DICT_DEBUG is true when built with the dictdebug tag.
+const DICT_DEBUG = true
+
+// debugValidate validates the dictionary after a structural change when built with the
+// dictdebug tag, panicking on the first broken invariant. To keep the cost amortized O(1),
+// the dictionary is only validated when its number of entries is zero or a power of two.
+func (d *Dict[K, V]) debugValidate() {
+	if bits.OnesCount64(uint64(d.mainTable().used+d.rehashingTable().used)) > 1 {
+		return
+	}
+	if err := d.Validate(); err != nil {
+		panic(err)
+	}
+}
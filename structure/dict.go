@@ -2,45 +2,118 @@ package structure
 
 import (
 	"fmt"
+	"math/bits"
+	"time"
 
 	"github.com/dmarro89/go-redis-hashtable/hashing"
 )
 
 const (
-	INITIAL_SIZE = int64(4)
-	MAX_SIZE     = 1 << 63
+	INITIAL_SIZE       = int64(4)
+	MAX_SIZE           = 1 << 63
+	HASHTABLE_MIN_FILL = int64(8)
 )
 
-type IDict interface {
-	Set(key string, value string) error
-	Get(key string) string
-	Delete(key string) error
-	GetAllItems() map[string]string
+type IDict[K comparable, V any] interface {
+	Set(key K, value V) error
+	Get(key K) V
+	Lookup(key K) (V, bool)
+	Delete(key K) error
+	SetIfAbsent(key K, value V) bool
+	SetIfPresent(key K, value V) bool
+	GetAndSet(key K, value V) (V, bool)
+	GetAndDelete(key K) (V, bool)
+	CompareAndSwap(key K, old, new V) bool
+	Upsert(key K, fn func(old V, exists bool) V) V
+	GetAllItems() map[K]V
+	Scan(cursor uint64, fn func(key K, value V)) uint64
+	Len() int
 }
 
-type Dict struct {
-	hashTables [2]*HashTable
-	rehashidx  int
-	hasher     hashing.IHasher
+type Dict[K comparable, V any] struct {
+	hashTables  [2]*HashTable[K, V]
+	rehashidx   int
+	pauseRehash int
+	dictType    *DictType[K, V]
+	// hasher is dictType.HashFunction, kept here to spare an indirection on every lookup
+	hasher      hashing.IHasher[K]
+	version     uint64
+	memoryLimit int64
+	// vetoedSize is the size of the main table whose growth the memory limit refused, 0 if none:
+	// the entries added meanwhile only increase the usage, so the refusal holds until the size
+	// of the table or the limit changes
+	vetoedSize int64
+	// snapshots is the number of live snapshots, entries are shared only while it is positive
+	snapshots int
 }
 
-// NewSipHashDict returns a new instance of Dict.
+// NewDict returns a new instance of Dict whose keys are hashed by the given hasher.
 //
-// The function does not take any parameters.
-// It returns a pointer to Dict.
-func NewSipHashDict() IDict {
-	return &Dict{
-		hashTables: [2]*HashTable{NewHashTable(0), NewHashTable(0)},
+// Parameters:
+// - hasher: the hashing.IHasher used to compute the bucket of each key.
+//
+// Returns:
+// - IDict: a pointer to the newly created Dict.
+func NewDict[K comparable, V any](hasher hashing.IHasher[K]) IDict[K, V] {
+	return NewDictWithType(&DictType[K, V]{HashFunction: hasher})
+}
+
+// NewDictWithType returns a new instance of Dict customized by the callbacks of the given DictType.
+//
+// Parameters:
+// - dictType: the callbacks of the Dict, its HashFunction must not be nil.
+//
+// Returns:
+// - IDict: a pointer to the newly created Dict.
+func NewDictWithType[K comparable, V any](dictType *DictType[K, V]) IDict[K, V] {
+	return &Dict[K, V]{
+		hashTables: [2]*HashTable[K, V]{NewHashTable[K, V](0), NewHashTable[K, V](0)},
 		rehashidx:  -1,
-		hasher:     hashing.NewSip24Hasher(),
+		dictType:   dictType,
+		hasher:     dictType.HashFunction,
 	}
 }
 
+// NewCaseInsensitiveDict returns a new instance of a string/string Dict whose keys are
+// compared and hashed ignoring the case of their ASCII letters, like the Redis dictionaries
+// of command and config names. Keys keep the casing used when they were first inserted.
+//
+// The function does not take any parameters.
+// It returns a pointer to Dict.
+func NewCaseInsensitiveDict() IDict[string, string] {
+	hasher := hashing.NewCaseInsensitiveHasher(hashing.NewSip24Hasher())
+	return NewDictWithType(&DictType[string, string]{
+		HashFunction: hasher,
+		KeyCompare:   hasher.Equal,
+	})
+}
+
+// NewSipHashDict returns a new instance of a string/string Dict hashed with SipHash-2-4.
+// Each Dict is keyed with its own random seed.
+//
+// The function does not take any parameters.
+// It returns a pointer to Dict.
+func NewSipHashDict() IDict[string, string] {
+	return NewDict[string, string](hashing.NewSip24Hasher())
+}
+
+// NewSipHashDictWithSeed returns a new instance of a string/string Dict hashed with SipHash-2-4
+// keyed with the given seed, so that its bucket layout is reproducible.
+//
+// Parameters:
+// - seed: the 16-byte SipHash key.
+//
+// Returns:
+// - IDict: a pointer to the newly created Dict.
+func NewSipHashDictWithSeed(seed [16]byte) IDict[string, string] {
+	return NewDict[string, string](hashing.NewSip24HasherWithSeed(seed))
+}
+
 // mainTable returns the main hash table of the Dict.
 //
 // No parameters.
 // Returns a pointer to a HashTable.
-func (d *Dict) mainTable() *HashTable {
+func (d *Dict[K, V]) mainTable() *HashTable[K, V] {
 	return d.hashTables[0]
 }
 
@@ -48,10 +121,39 @@ func (d *Dict) mainTable() *HashTable {
 //
 // No parameters.
 // Returns *HashTable.
-func (d *Dict) rehashingTable() *HashTable {
+func (d *Dict[K, V]) rehashingTable() *HashTable[K, V] {
 	return d.hashTables[1]
 }
 
+// Seed returns the seed of the hasher of the Dict.
+//
+// No parameters.
+//
+// Returns:
+// - [16]byte: the seed of the hasher.
+// - bool: false if the hasher is not keyed with a 16-byte seed.
+func (d *Dict[K, V]) Seed() ([16]byte, bool) {
+	if seeded, ok := d.hasher.(hashing.ISeededHasher); ok {
+		return seeded.Seed(), true
+	}
+	return [16]byte{}, false
+}
+
+// compareKeys reports whether two keys are equal, using the KeyCompare
+// callback of the DictType if it has one.
+//
+// Parameters:
+// - a, b: the keys to compare.
+//
+// Returns:
+// - bool: true if the keys are equal, false otherwise.
+func (d *Dict[K, V]) compareKeys(a, b K) bool {
+	if d.dictType.KeyCompare != nil {
+		return d.dictType.KeyCompare(a, b)
+	}
+	return a == b
+}
+
 // nextPower calculates the next power of 2 greater than the given size.
 //
 // Parameters:
@@ -75,21 +177,30 @@ func nextPower(size int64) int64 {
 	return size + 1
 }
 
-// expand expands the dictionary to a new size if necessary.
+// expand resizes the dictionary to a new size if necessary.
+// The new size may also be smaller than the current one, in which case the
+// entries are migrated into the smaller table by the incremental rehashing.
+// Growing an allocated table may be vetoed by the memory limit or the ExpandAllowed callback.
 //
-// newSize: the new size to expand the dictionary to.
+// newSize: the new size to resize the dictionary to.
 // The function does not return anything.
-func (d *Dict) expand(newSize int64) {
+func (d *Dict[K, V]) expand(newSize int64) {
 	if d.isRehashing() || d.mainTable().used > newSize {
 		return
 	}
 
+	// Rehashing to a table of the same size is useless
 	nextSize := nextPower(newSize)
-	if d.mainTable().used >= nextSize {
+	if nextSize == d.mainTable().size {
 		return
 	}
 
-	newHashTable := NewHashTable(nextSize)
+	if nextSize > d.mainTable().size && d.mainTable().size > 0 && !d.expandAllowed(nextSize) {
+		return
+	}
+
+	newHashTable := NewHashTable[K, V](nextSize)
+	d.vetoedSize = 0
 
 	if d.mainTable() == nil || len(d.mainTable().table) == 0 {
 		*d.mainTable() = *newHashTable
@@ -104,7 +215,7 @@ func (d *Dict) expand(newSize int64) {
 //
 // No parameters.
 // No return values.
-func (d *Dict) expandIfNeeded() {
+func (d *Dict[K, V]) expandIfNeeded() {
 	if d.isRehashing() {
 		return
 	}
@@ -117,105 +228,141 @@ func (d *Dict) expandIfNeeded() {
 	}
 }
 
-// keyIndex returns the index of the given key in the dictionary.
+// shrinkIfNeeded checks if the dictionary is filled for less than 1/HASHTABLE_MIN_FILL
+// and, if so, shrinks it to the minimal size that contains all the entries.
 //
-// It takes in a key string and randomBytes []byte as parameters.
-// It returns an integer representing the index of the key in the dictionary.
-func (d *Dict) keyIndex(key string) int {
-	d.expandIfNeeded()
-	hash := d.hasher.Digest(key)
-
-	var index int
-	for i := 0; i <= 1; i++ {
-		hashTable := d.hashTables[i]
-		index = int(hash & hashTable.sizemask)
-
-		for entry := hashTable.table[index]; entry != nil; entry = entry.next {
-			if entry.key == key {
-				return -1
-			}
-		}
-
-		if index == -1 || !d.isRehashing() {
-			break
-		}
+// No parameters.
+// No return values.
+func (d *Dict[K, V]) shrinkIfNeeded() {
+	if d.isRehashing() {
+		return
 	}
 
-	return index
+	if d.mainTable().size > INITIAL_SIZE && d.mainTable().used*HASHTABLE_MIN_FILL <= d.mainTable().size {
+		d.expand(d.mainTable().used)
+	}
 }
 
-// add adds a key-value pair to the dictionary.
+// findPositionForInsert looks up a key once, like Redis dictFindPositionForInsert: it returns
+// the entry of the key if it is in the dictionary, or the bucket where a new entry for the key
+// must be linked otherwise. The incremental rehashing step and the expansion are only performed
+// when the key is missing, so that overwriting a value never changes the structure of the tables,
+// and before the position is computed, so that it stays valid for insertAt.
 //
 // Parameters:
-// - key: The key to add.
-// - value: The value associated with the key.
+// - key: the key to look up.
 //
 // Returns:
-// - error: An error if the key already exists in the dictionary.
-func (d *Dict) add(key string, value string) error {
-	index := d.keyIndex(key)
+// - *DictEntry: the entry of the key, or nil if the key is not in the dictionary.
+// - *HashTable: the hash table where the key must be inserted, if not found.
+// - uint64: the index of the bucket where the key must be inserted, if not found.
+func (d *Dict[K, V]) findPositionForInsert(key K) (*DictEntry[K, V], *HashTable[K, V], uint64) {
+	return d.findPositionForHash(d.hasher.Digest(key), key)
+}
 
-	if index == -1 {
-		return fmt.Errorf(`unexpectedly found an entry with the same key when trying to add #{ %s } / #{ %s }`, key, value)
+// findPositionForHash is findPositionForInsert for a key whose digest is already computed.
+//
+// Parameters:
+// - hash: the digest of the key.
+// - key: the key to look up.
+//
+// Returns:
+// - *DictEntry: the entry of the key, or nil if the key is not in the dictionary.
+// - *HashTable: the hash table where the key must be inserted, if not found.
+// - uint64: the index of the bucket where the key must be inserted, if not found.
+func (d *Dict[K, V]) findPositionForHash(hash uint64, key K) (*DictEntry[K, V], *HashTable[K, V], uint64) {
+	if entry := d.findEntry(hash, key); entry != nil {
+		return entry, nil, 0
 	}
 
-	hashTable := d.mainTable()
 	if d.isRehashing() {
 		d.rehashStep()
-		hashTable = d.mainTable()
-		if d.isRehashing() {
-			hashTable = d.rehashingTable()
-		}
 	}
+	d.expandIfNeeded()
 
-	entry := hashTable.table[index]
-
-	for entry != nil && entry.key != key {
-		entry = entry.next
+	// While rehashing, new entries always go to the rehashing table
+	hashTable := d.mainTable()
+	if d.isRehashing() {
+		hashTable = d.rehashingTable()
 	}
+	return nil, hashTable, hash & hashTable.sizemask
+}
 
-	if entry == nil {
-		entry = NewDictEntry(key, value)
-		entry.next = hashTable.table[index]
-		hashTable.table[index] = entry
-		hashTable.used++
+// insertAt links a new entry at the head of the bucket returned by findPositionForInsert,
+// duplicating its key and value with the KeyDup and ValDup callbacks.
+//
+// Parameters:
+// - hashTable: the hash table returned by findPositionForInsert.
+// - index: the index of the bucket returned by findPositionForInsert.
+// - key: the key of the new entry.
+// - value: the value of the new entry.
+//
+// No return values.
+func (d *Dict[K, V]) insertAt(hashTable *HashTable[K, V], index uint64, key K, value V) {
+	d.writableTable(hashTable)
+	entry := d.newEntry(d.dupKey(key), d.dupValue(value))
+	entry.next = hashTable.table[index]
+	hashTable.table[index] = entry
+	hashTable.used++
+	d.debugValidate()
+}
+
+// add adds a key-value pair to the dictionary.
+//
+// Parameters:
+// - key: The key to add.
+// - value: The value associated with the key.
+//
+// Returns:
+// - error: An error wrapping ErrKeyExists if the key already exists in the dictionary.
+func (d *Dict[K, V]) add(key K, value V) error {
+	entry, hashTable, index := d.findPositionForInsert(key)
+	if entry != nil {
+		return fmt.Errorf(`cannot add key %v: %w`, key, ErrKeyExists)
 	}
 
+	d.insertAt(hashTable, index, key, value)
 	return nil
 }
 
-// rehashStep returns the result of calling the rehash function on the Dict object with an argument of 1.
+// rehashStep performs a single step of incremental rehashing, unless rehashing is paused
+// by a safe iterator.
 //
 // No parameters.
-// Returns an integer.
-func (d *Dict) rehashStep() {
+// No return values.
+func (d *Dict[K, V]) rehashStep() {
+	if d.pauseRehash > 0 {
+		return
+	}
 	d.rehash(1)
 }
 
 // rehash rehashes the dictionary with a new size.
 //
-// n is the new size of the dictionary.
-// Returns 0 if the rehashing is not in progress.
-// Returns 1 if the rehashing is in progress.
-func (d *Dict) rehash(n int) {
+// n is the number of buckets to migrate from the main table to the rehashing table.
+// Returns false if the rehashing is not in progress anymore.
+// Returns true if there are still entries to migrate.
+func (d *Dict[K, V]) rehash(n int) bool {
 	emptyVisits := n * 10
 	if !d.isRehashing() {
-		return
+		return false
 	}
 
 	for n > 0 && d.mainTable().used != 0 {
 		n--
 
-		var entry *DictEntry
+		var entry *DictEntry[K, V]
 
 		for len(d.mainTable().table) == 0 || d.mainTable().table[d.rehashidx] == nil {
 			d.rehashidx++
 			emptyVisits--
 			if emptyVisits == 0 {
-				return
+				return true
 			}
 		}
 
+		d.writableBucket(d.mainTable(), uint64(d.rehashidx))
+		d.writableTable(d.rehashingTable())
 		entry = d.mainTable().table[d.rehashidx]
 
 		for entry != nil {
@@ -234,18 +381,88 @@ func (d *Dict) rehash(n int) {
 	}
 
 	if d.mainTable().used == 0 {
+		shrinking := d.rehashingTable().size < d.mainTable().size
 		d.hashTables[0] = d.rehashingTable()
-		d.hashTables[1] = NewHashTable(0)
+		d.hashTables[1] = NewHashTable[K, V](0)
 		d.rehashidx = -1
-		return
+		// The deletes performed while shrinking could not shrink the table further
+		if shrinking {
+			d.shrinkIfNeeded()
+		}
+		d.debugValidate()
+		return d.isRehashing()
+	}
+
+	d.debugValidate()
+	return true
+}
+
+// RehashSteps performs n steps of incremental rehashing, each one migrating a bucket
+// from the main table to the rehashing table. Nothing is done while rehashing is paused
+// by a safe iterator.
+//
+// Parameters:
+// - n: the number of buckets to migrate.
+//
+// Returns:
+// - bool: true if there are still entries to migrate, false otherwise.
+func (d *Dict[K, V]) RehashSteps(n int) bool {
+	if d.pauseRehash > 0 {
+		return d.isRehashing()
+	}
+	return d.rehash(n)
+}
+
+// RehashMilliseconds performs incremental rehashing in batches of 100 steps
+// for at most the given amount of milliseconds, like Redis dictRehashMilliseconds.
+// Nothing is done while rehashing is paused by a safe iterator.
+//
+// Parameters:
+// - ms: the time budget in milliseconds.
+//
+// Returns:
+// - int: the number of rehashing steps performed.
+func (d *Dict[K, V]) RehashMilliseconds(ms int) int {
+	if d.pauseRehash > 0 {
+		return 0
+	}
+
+	return rehashMilliseconds(ms, d.isRehashing, func() { d.rehash(1) })
+}
+
+// rehashMilliseconds performs rehashing steps in batches of 100 for at most the given amount
+// of milliseconds, like Redis dictRehashMilliseconds. It is shared by the dictionaries that
+// rehash incrementally. The steps are counted one by one, so that the batch completing the
+// rehashing counts too.
+//
+// Parameters:
+// - ms: the time budget in milliseconds.
+// - isRehashing: reports whether the rehashing is still in progress.
+// - step: performs a single rehashing step.
+//
+// Returns:
+// - int: the number of rehashing steps performed.
+func rehashMilliseconds(ms int, isRehashing func() bool, step func()) int {
+	start := time.Now()
+	budget := time.Duration(ms) * time.Millisecond
+	rehashes := 0
+	for isRehashing() {
+		for i := 0; i < 100 && isRehashing(); i++ {
+			step()
+			rehashes++
+		}
+		if time.Since(start) > budget {
+			break
+		}
 	}
+	return rehashes
 }
 
 // isRehashing checks if the rehash index of the Dict struct is not equal to -1.
 //
 // It does not take any parameters.
 // It returns a boolean value indicating whether the rehash index is not equal to -1.
-func (d *Dict) isRehashing() bool {
+func (d *Dict[K, V]) isRehashing() bool {
 	return d.rehashidx != -1
 }
 
@@ -256,14 +473,24 @@ func (d *Dict) isRehashing() bool {
 //
 // Return:
 // - *DictEntry: the DictEntry associated with the given key, or nil if not found.
-func (d *Dict) getEntry(key string) *DictEntry {
+func (d *Dict[K, V]) getEntry(key K) *DictEntry[K, V] {
 	if d.mainTable().used == 0 && d.rehashingTable().used == 0 {
 		return nil
 	}
 
-	hash := d.hasher.Digest(key)
+	return d.findEntry(d.hasher.Digest(key), key)
+}
 
-	for ind, hashTable := range []*HashTable{d.mainTable(), d.rehashingTable()} {
+// findEntry returns the DictEntry associated with the given key, whose digest is already computed.
+//
+// Parameters:
+// - hash: the digest of the key.
+// - key: the key to search for in the Dict.
+//
+// Return:
+// - *DictEntry: the DictEntry associated with the given key, or nil if not found.
+func (d *Dict[K, V]) findEntry(hash uint64, key K) *DictEntry[K, V] {
+	for ind, hashTable := range []*HashTable[K, V]{d.mainTable(), d.rehashingTable()} {
 		if hashTable == nil || len(hashTable.table) == 0 || (ind == 1 && !d.isRehashing()) {
 			continue
 		}
@@ -272,7 +499,7 @@ func (d *Dict) getEntry(key string) *DictEntry {
 		entry := hashTable.table[index]
 
 		for entry != nil {
-			if entry.key == key {
+			if d.compareKeys(entry.key, key) {
 				return entry
 			}
 			entry = entry.next
@@ -282,6 +509,41 @@ func (d *Dict) getEntry(key string) *DictEntry {
 	return nil
 }
 
+// swapValue sets the value of an entry found by getEntry, copying its chain first
+// if it is shared with a snapshot, and returns the value it replaced.
+//
+// Parameters:
+// - entry: the entry to update.
+// - value: the new value, duplicated with the ValDup callback.
+//
+// Returns:
+// - V: the previous value of the entry, now owned by the caller.
+func (d *Dict[K, V]) swapValue(entry *DictEntry[K, V], value V) V {
+	if d.isShared(entry) {
+		entry = d.writableEntry(entry.key)
+	}
+	old := entry.value
+	entry.value = d.dupValue(value)
+	entry.sharedValue = false
+	return old
+}
+
+// updateEntry sets the value of an entry found by getEntry and releases the value it replaced
+// with the ValDestructor callback, like Redis dictReplace, unless a snapshot can still return it.
+//
+// Parameters:
+// - entry: the entry to update.
+// - value: the new value.
+//
+// No return values.
+func (d *Dict[K, V]) updateEntry(entry *DictEntry[K, V], value V) {
+	shared := d.isValueShared(entry)
+	old := d.swapValue(entry, value)
+	if !shared {
+		d.destroyValue(old)
+	}
+}
+
 // delete deletes a key from the dictionary and returns the corresponding value.
 //
 // Parameters:
@@ -289,33 +551,53 @@ func (d *Dict) getEntry(key string) *DictEntry {
 //
 // Return:
 // - *DictEntry: the deleted DictEntry if found, otherwise nil.
-func (d *Dict) delete(key string) *DictEntry {
+func (d *Dict[K, V]) delete(key K) *DictEntry[K, V] {
 	if d.mainTable().used == 0 && d.rehashingTable().used == 0 {
 		return nil
 	}
 
+	return d.unlinkHash(d.hasher.Digest(key), key)
+}
+
+// unlinkHash is delete for a key whose digest is already computed.
+//
+// Parameters:
+// - hash: the digest of the key.
+// - key: the key to be deleted from the dictionary.
+//
+// Return:
+// - *DictEntry: the deleted DictEntry if found, otherwise nil.
+func (d *Dict[K, V]) unlinkHash(hash uint64, key K) *DictEntry[K, V] {
 	if d.isRehashing() {
 		d.rehashStep()
 	}
 
-	hash := d.hasher.Digest(key)
-
-	for i, hashTable := range []*HashTable{d.mainTable(), d.rehashingTable()} {
-		if hashTable == nil || (i == 1 && !d.isRehashing()) {
+	for i, hashTable := range []*HashTable[K, V]{d.mainTable(), d.rehashingTable()} {
+		if hashTable == nil || len(hashTable.table) == 0 || (i == 1 && !d.isRehashing()) {
 			continue
 		}
 		index := hash & hashTable.sizemask
 		entry := hashTable.table[index]
-		var previousEntry *DictEntry
+		var previousEntry *DictEntry[K, V]
 
 		for entry != nil {
-			if entry.key == key {
+			if d.compareKeys(entry.key, key) {
+				// Unlinking modifies the chain, which must not be shared with a snapshot
+				if d.snapshots > 0 {
+					d.writableBucket(hashTable, index)
+					entry, previousEntry = hashTable.table[index], nil
+					for !d.compareKeys(entry.key, key) {
+						previousEntry, entry = entry, entry.next
+					}
+				}
 				if previousEntry != nil {
 					previousEntry.next = entry.next
 				} else {
 					hashTable.table[index] = entry.next
 				}
 				hashTable.used--
+				d.shrinkIfNeeded()
+				d.debugValidate()
 				return entry
 			}
 			previousEntry = entry
@@ -332,54 +614,160 @@ func (d *Dict) delete(key string) *DictEntry {
 // - key: the key to look up in the dictionary.
 //
 // Return:
-// - interface{}: the value associated with the key, or nil if the key is not found.
-func (d *Dict) Get(key string) string {
-	entry := d.getEntry(key)
+// - V: the value associated with the key, or the zero value of V if the key is not found.
+func (d *Dict[K, V]) Get(key K) V {
+	value, _ := d.Lookup(key)
+	return value
+}
+
+// Lookup returns the value associated with the given key in the dictionary and whether it was found,
+// so that a missing key can be told apart from a key holding the zero value.
+//
+// Parameters:
+// - key: the key to look up in the dictionary.
+//
+// Return:
+// - V: the value associated with the key, or the zero value of V if the key is not found.
+// - bool: true if the key is in the dictionary, false otherwise.
+func (d *Dict[K, V]) Lookup(key K) (V, bool) {
+	return d.lookupHash(d.hasher.Digest(key), key)
+}
+
+// lookupHash is Lookup for a key whose digest is already computed.
+//
+// Parameters:
+// - hash: the digest of the key.
+// - key: the key to look up in the dictionary.
+//
+// Return:
+// - V: the value associated with the key, or the zero value of V if the key is not found.
+// - bool: true if the key is in the dictionary, false otherwise.
+func (d *Dict[K, V]) lookupHash(hash uint64, key K) (V, bool) {
+	entry := d.findEntry(hash, key)
 	if entry == nil {
-		return ""
+		var zero V
+		return zero, false
 	}
-	return entry.value
+	return entry.value, true
 }
 
 // Set sets the value of a key in the dictionary.
+// The key is hashed once and its chain walked once, both to update and to insert.
 //
 // Parameters:
 //   - key: the key to set the value for.
 //   - value: the value to set.
 //
 // Returns:
-//   - error: an error if the key already exists in the dictionary.
-func (d *Dict) Set(key string, value string) error {
-	entry := d.getEntry(key)
+//   - error: always nil, since an existing key is updated.
+func (d *Dict[K, V]) Set(key K, value V) error {
+	return d.setHash(d.hasher.Digest(key), key, value)
+}
+
+// setHash is Set for a key whose digest is already computed.
+//
+// Parameters:
+//   - hash: the digest of the key.
+//   - key: the key to set the value for.
+//   - value: the value to set.
+//
+// Returns:
+//   - error: always nil, since an existing key is updated.
+func (d *Dict[K, V]) setHash(hash uint64, key K, value V) error {
+	entry, hashTable, index := d.findPositionForHash(hash, key)
 	if entry != nil {
-		entry.value = value
+		d.updateEntry(entry, value)
 		return nil
 	}
-	return d.add(key, value)
+
+	d.insertAt(hashTable, index, key, value)
+	return nil
+}
+
+// Delete deletes an entry from the dictionary, releasing its key and value
+// with the destructors of the DictType.
+//
+// Parameters:
+// - key: the key of the entry to be deleted.
+//
+// Returns:
+// - error: ErrKeyNotFound if the entry is not found.
+func (d *Dict[K, V]) Delete(key K) error {
+	return d.deleteHash(d.hasher.Digest(key), key)
 }
 
-// Delete deletes an entry from the dictionary.
+// deleteHash is Delete for a key whose digest is already computed.
 //
 // Parameters:
+// - hash: the digest of the key.
 // - key: the key of the entry to be deleted.
 //
 // Returns:
-// - error: if the entry is not found.
-func (d *Dict) Delete(key string) error {
-	dictEntry := d.delete(key)
+// - error: ErrKeyNotFound if the entry is not found.
+func (d *Dict[K, V]) deleteHash(hash uint64, key K) error {
+	dictEntry := d.unlinkHash(hash, key)
 	if dictEntry == nil {
-		return fmt.Errorf(`entry not found`)
+		return ErrKeyNotFound
 	}
+
+	d.FreeUnlinked(dictEntry)
 	return nil
 }
 
+// Resize shrinks the dictionary to the minimal power of two that contains all the entries.
+// The entries are migrated into the smaller table by the incremental rehashing.
+//
+// No parameters.
+//
+// Returns:
+// - error: if the dictionary is already rehashing.
+func (d *Dict[K, V]) Resize() error {
+	if d.isRehashing() {
+		return fmt.Errorf(`cannot resize while rehashing`)
+	}
+
+	d.expand(d.mainTable().used)
+	return nil
+}
+
+// Clear removes all the entries of the dictionary, releasing their keys and values with the
+// destructors of the DictType, like Redis dictEmpty, except the ones a snapshot can still return.
+// Any rehashing in progress is abandoned.
+//
+// No parameters.
+// No return values.
+func (d *Dict[K, V]) Clear() {
+	if d.dictType.KeyDestructor != nil || d.dictType.ValDestructor != nil {
+		for _, hashTable := range d.hashTables {
+			for _, entry := range hashTable.table {
+				for ; entry != nil; entry = entry.next {
+					d.freeEntry(entry)
+				}
+			}
+		}
+	}
+
+	*d.mainTable() = *NewHashTable[K, V](0)
+	*d.rehashingTable() = *NewHashTable[K, V](0)
+	d.rehashidx = -1
+	d.debugValidate()
+}
+
+// Len returns the number of entries in the dictionary.
+//
+// No parameters.
+// Returns the number of entries of both hash tables.
+func (d *Dict[K, V]) Len() int {
+	return int(d.mainTable().used + d.rehashingTable().used)
+}
+
 // GetAllKeys retrieves all keys from the hash table.
 // It iterates over both hash tables in the Dict struct (main table and rehashing table).
 // Each bucket may contain a linked list of entries (DictEntry) due to hash collisions,
 // so it traverses through these linked lists to collect all keys.
 // This function supports the dynamic resizing and rehashing mechanism.
-func (d *Dict) GetAllItems() map[string]string {
-	items := make(map[string]string)
+func (d *Dict[K, V]) GetAllItems() map[K]V {
+	items := make(map[K]V)
 
 	// Iterate over both hash tables (HashTable[2])
 	for _, hashtable := range d.hashTables {
@@ -397,3 +785,79 @@ func (d *Dict) GetAllItems() map[string]string {
 
 	return items
 }
+
+// Scan iterates incrementally over the elements of the dictionary, like Redis SCAN.
+//
+// The first call uses a cursor of 0; each call visits one bucket (or, while rehashing,
+// one bucket of the smaller table and all the buckets of the larger table that expand from it),
+// calls fn for every element found and returns the cursor to use for the next call.
+// The iteration is complete when the returned cursor is 0.
+//
+// The cursor is advanced by incrementing its reversed bits, so that every element present
+// for the whole duration of the scan is returned at least once even if the tables are
+// resized between calls. Elements may be returned more than once.
+// fn must not modify the dictionary.
+//
+// Parameters:
+// - cursor: the cursor returned by the previous call, or 0 to start a new iteration.
+// - fn: the function called for each element of the visited buckets.
+//
+// Returns:
+// - uint64: the cursor for the next call, or 0 if the iteration is complete.
+func (d *Dict[K, V]) Scan(cursor uint64, fn func(key K, value V)) uint64 {
+	if d.mainTable().used == 0 && d.rehashingTable().used == 0 {
+		return 0
+	}
+
+	if !d.isRehashing() {
+		table := d.mainTable()
+		mask := table.sizemask
+		scanBucket(table.table[cursor&mask], fn)
+
+		return nextCursor(cursor, mask)
+	}
+
+	small, large := d.mainTable(), d.rehashingTable()
+	if small.size > large.size {
+		small, large = large, small
+	}
+	smallMask, largeMask := small.sizemask, large.sizemask
+
+	// Emit the bucket of the smaller table pointed by the cursor
+	scanBucket(small.table[cursor&smallMask], fn)
+
+	// Emit all the buckets of the larger table that are expansions of the bucket of the smaller one
+	for {
+		scanBucket(large.table[cursor&largeMask], fn)
+		cursor = nextCursor(cursor, largeMask)
+		if cursor&(smallMask^largeMask) == 0 {
+			break
+		}
+	}
+
+	return cursor
+}
+
+// scanBucket calls fn for each entry of the chain starting at entry.
+func scanBucket[K comparable, V any](entry *DictEntry[K, V], fn func(key K, value V)) {
+	for entry != nil {
+		next := entry.next
+		fn(entry.key, entry.value)
+		entry = next
+	}
+}
+
+// nextCursor increments the reversed bits of cursor that are covered by mask.
+//
+// Parameters:
+// - cursor: the current scan cursor.
+// - mask: the sizemask of the table being scanned.
+//
+// Returns:
+// - uint64: the next scan cursor.
+func nextCursor(cursor uint64, mask uint64) uint64 {
+	cursor |= ^mask
+	cursor = bits.Reverse64(cursor)
+	cursor++
+	return bits.Reverse64(cursor)
+}
@@ -1,23 +1,45 @@
 package structure
 
-type DictEntry struct {
-	next  *DictEntry
-	key   string
-	value string
+type DictEntry[K comparable, V any] struct {
+	next  *DictEntry[K, V]
+	key   K
+	value V
+	// version is the version of the Dict when the entry was created,
+	// entries older than the last Snapshot are shared with it while it is live
+	version uint64
+	// sharedKey and sharedValue are set on the copies of the entries shared with a Snapshot,
+	// whose key and value can still be returned by the snapshot
+	sharedKey, sharedValue bool
 }
 
 // NewDictEntry creates a new DictEntry with the given key and value.
 //
 // Parameters:
-// - key: a string representing the key of the entry.
-// - value: an interface{} representing the value of the entry.
+// - key: the key of the entry.
+// - value: the value of the entry.
 //
 // Returns:
 // - *DictEntry: a pointer to the newly created DictEntry.
-func NewDictEntry(key string, value string) *DictEntry {
-	return &DictEntry{
+func NewDictEntry[K comparable, V any](key K, value V) *DictEntry[K, V] {
+	return &DictEntry[K, V]{
 		key:   key,
 		value: value,
 		next:  nil,
 	}
 }
+
+// Key returns the key of the entry.
+//
+// No parameters.
+// Returns the key.
+func (e *DictEntry[K, V]) Key() K {
+	return e.key
+}
+
+// Value returns the value of the entry.
+//
+// No parameters.
+// Returns the value.
+func (e *DictEntry[K, V]) Value() V {
+	return e.value
+}
@@ -0,0 +1,112 @@
+package structure
+
+import (
+	"unsafe"
+
+	"github.com/dmarro89/go-redis-hashtable/hashing"
+)
+
+const (
+	// FORCE_RESIZE_RATIO is the load factor above which the dictionary is expanded
+	// even if ExpandAllowed vetoes it, like Redis dict_force_resize_ratio.
+	FORCE_RESIZE_RATIO = int64(4)
+)
+
+// DictType holds the callbacks that customize a Dict, like the Redis dictType.
+// Only HashFunction is required: a nil callback falls back to the default behavior.
+// The destructors run when a key or a value leaves the live dictionary, except if it was in the
+// dictionary when a Snapshot was taken: the snapshot can still return it, and it is left to the
+// garbage collector.
+type DictType[K comparable, V any] struct {
+	// HashFunction computes the digest of a key.
+	HashFunction hashing.IHasher[K]
+	// KeyDup returns the key stored when a new entry is added.
+	KeyDup func(key K) K
+	// ValDup returns the value stored when an entry is added or updated.
+	ValDup func(value V) V
+	// KeyCompare reports whether two keys are equal, == is used if nil.
+	KeyCompare func(a, b K) bool
+	// KeyDestructor releases the key of an entry removed from the dictionary.
+	KeyDestructor func(key K)
+	// ValDestructor releases a value removed from the dictionary or overwritten.
+	ValDestructor func(value V)
+	// ExpandAllowed is called before the dictionary grows, with the memory the new bucket
+	// array needs and the current load factor: returning false postpones the expansion.
+	ExpandAllowed func(moreMem int64, usedRatio float64) bool
+}
+
+// dupKey returns the key to store for a new entry.
+func (d *Dict[K, V]) dupKey(key K) K {
+	if d.dictType.KeyDup != nil {
+		return d.dictType.KeyDup(key)
+	}
+	return key
+}
+
+// dupValue returns the value to store in an entry.
+func (d *Dict[K, V]) dupValue(value V) V {
+	if d.dictType.ValDup != nil {
+		return d.dictType.ValDup(value)
+	}
+	return value
+}
+
+// destroyKey releases the key of an entry removed from the dictionary.
+func (d *Dict[K, V]) destroyKey(key K) {
+	if d.dictType.KeyDestructor != nil {
+		d.dictType.KeyDestructor(key)
+	}
+}
+
+// destroyValue releases a value removed from the dictionary or overwritten.
+func (d *Dict[K, V]) destroyValue(value V) {
+	if d.dictType.ValDestructor != nil {
+		d.dictType.ValDestructor(value)
+	}
+}
+
+// freeEntry releases the key and the value of an entry removed from the dictionary,
+// except the ones a snapshot can still return.
+func (d *Dict[K, V]) freeEntry(entry *DictEntry[K, V]) {
+	if !d.isKeyShared(entry) {
+		d.destroyKey(entry.key)
+	}
+	if !d.isValueShared(entry) {
+		d.destroyValue(entry.value)
+	}
+}
+
+// expandAllowed checks if the main table may grow to the given number of buckets.
+// The growth is always allowed once the load factor exceeds FORCE_RESIZE_RATIO. Otherwise it is
+// refused if the new bucket array would take the dictionary over its memory limit, and then the
+// ExpandAllowed callback is consulted, like Redis dictTypeResizeAllowed.
+//
+// Parameters:
+// - nextSize: the number of buckets of the new table.
+//
+// Returns:
+// - bool: true if the expansion is allowed.
+func (d *Dict[K, V]) expandAllowed(nextSize int64) bool {
+	main := d.mainTable()
+	if main.used >= main.size*FORCE_RESIZE_RATIO {
+		return true
+	}
+
+	moreMem := nextSize * int64(unsafe.Sizeof((*DictEntry[K, V])(nil)))
+	if d.memoryLimit > 0 {
+		// MemoryUsage is O(n), it is not called again on each insert once the growth is refused
+		if d.vetoedSize == main.size {
+			return false
+		}
+		if d.MemoryUsage()+moreMem > d.memoryLimit {
+			d.vetoedSize = main.size
+			return false
+		}
+	}
+
+	if d.dictType.ExpandAllowed == nil {
+		return true
+	}
+
+	return d.dictType.ExpandAllowed(moreMem, float64(main.used)/float64(main.size))
+}
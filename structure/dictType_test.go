@@ -0,0 +1,177 @@
+package structure
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dmarro89/go-redis-hashtable/hashing"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTrackingDict returns a Dict whose destructors record the released keys and values.
+func newTrackingDict() (*Dict[string, string], *[]string, *[]string) {
+	var keys, values []string
+	d := NewDictWithType(&DictType[string, string]{
+		HashFunction:  hashing.NewSip24Hasher(),
+		KeyDup:        strings.Clone,
+		ValDup:        strings.ToUpper,
+		KeyDestructor: func(key string) { keys = append(keys, key) },
+		ValDestructor: func(value string) { values = append(values, value) },
+	}).(*Dict[string, string])
+	return d, &keys, &values
+}
+
+func TestDictTypeDup(t *testing.T) {
+	d, _, _ := newTrackingDict()
+
+	d.Set("key1", "value1")
+	assert.Equal(t, "VALUE1", d.Get("key1"), "ValDup should be applied on add")
+	d.Set("key1", "value2")
+	assert.Equal(t, "VALUE2", d.Get("key1"), "ValDup should be applied on overwrite")
+	d.Upsert("key2", func(old string, exists bool) string { return "value3" })
+	assert.Equal(t, "VALUE3", d.Get("key2"), "ValDup should be applied on upsert")
+}
+
+func TestDictTypeDestructors(t *testing.T) {
+	d, keys, values := newTrackingDict()
+
+	d.Set("key1", "value1")
+	d.Set("key1", "value2")
+	assert.Empty(t, *keys, "Overwriting should not release the key")
+	assert.Equal(t, []string{"VALUE1"}, *values, "Overwriting should release the old value")
+
+	assert.NoError(t, d.Delete("key1"))
+	assert.Equal(t, []string{"key1"}, *keys, "Delete should release the key")
+	assert.Equal(t, []string{"VALUE1", "VALUE2"}, *values, "Delete should release the value")
+
+	d.Set("key2", "value")
+	old, _ := d.GetAndSet("key2", "other")
+	assert.Equal(t, "VALUE", old, "Unexpected previous value")
+	value, _ := d.GetAndDelete("key2")
+	assert.Equal(t, "OTHER", value, "Unexpected deleted value")
+	assert.Equal(t, []string{"key1", "key2"}, *keys, "GetAndDelete should release the key")
+	assert.Equal(t, []string{"VALUE1", "VALUE2"}, *values, "Values handed to the caller should not be released")
+
+	d.Set("key3", "value")
+	entry := d.Unlink("key3")
+	assert.Equal(t, []string{"key1", "key2"}, *keys, "Unlink should not release the key")
+	d.FreeUnlinked(entry)
+	assert.Equal(t, []string{"key1", "key2", "key3"}, *keys, "FreeUnlinked should release the key")
+}
+
+func TestDictTypeClear(t *testing.T) {
+	d, keys, values := newTrackingDict()
+	for i := 0; i < 100; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	d.expand(1024)
+	assert.True(t, d.isRehashing(), "Dictionary should be rehashing")
+
+	d.Clear()
+	assert.Len(t, *keys, 100, "Clear should release every key")
+	assert.Len(t, *values, 100, "Clear should release every value")
+	assert.Equal(t, 0, d.Len(), "Unexpected length after clear")
+	assert.False(t, d.isRehashing(), "Clear should abandon the rehashing")
+
+	d.Set("key1", "value1")
+	assert.Equal(t, "VALUE1", d.Get("key1"), "Cleared dictionary should be usable")
+}
+
+func TestDictTypeExpandAllowed(t *testing.T) {
+	allowed := false
+	var requested []int64
+	d := NewDictWithType(&DictType[string, string]{
+		HashFunction: hashing.NewSip24Hasher(),
+		ExpandAllowed: func(moreMem int64, usedRatio float64) bool {
+			requested = append(requested, moreMem)
+			return allowed
+		},
+	}).(*Dict[string, string])
+
+	// The first allocation is always allowed
+	for i := 0; i < 16; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	assert.Equal(t, INITIAL_SIZE, d.mainTable().size, "Expansion should be vetoed")
+	assert.False(t, d.isRehashing(), "Expansion should be vetoed")
+	assert.Equal(t, int64(8*8), requested[0], "Unexpected memory requested for 8 buckets")
+
+	// Above FORCE_RESIZE_RATIO the expansion is forced
+	d.Set("key16", "value16")
+	assert.True(t, d.isRehashing(), "Expansion should be forced above FORCE_RESIZE_RATIO")
+	completeRehashing(d)
+
+	allowed = true
+	for i := 17; i < 200; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	completeRehashing(d)
+	assert.Equal(t, int64(256), d.mainTable().size, "Expansion should be allowed")
+}
+
+func TestDictTypeDestructorsWithSnapshot(t *testing.T) {
+	var keys, values []string
+	d := NewDictWithType(&DictType[string, []byte]{
+		HashFunction:  hashing.NewSip24Hasher(),
+		KeyDestructor: func(key string) { keys = append(keys, key) },
+		// The destructor wipes the value, as a value returned to a pool would be overwritten
+		ValDestructor: func(value []byte) {
+			values = append(values, string(value))
+			for i := range value {
+				value[i] = 0
+			}
+		},
+	}).(*Dict[string, []byte])
+	for i := 1; i <= 3; i++ {
+		d.Set(fmt.Sprintf("key%d", i), []byte(fmt.Sprintf("value%d", i)))
+	}
+	snapshot := d.Snapshot()
+
+	// The keys and values of the snapshot are not released
+	d.Set("key1", []byte("updated1"))
+	assert.NoError(t, d.Delete("key2"))
+	assert.Empty(t, keys, "Keys held by the snapshot should not be released")
+	assert.Empty(t, values, "Values held by the snapshot should not be released")
+
+	// The values stored after the snapshot are released
+	d.Set("key1", []byte("again1"))
+	d.Set("key4", []byte("value4"))
+	d.Set("key4", []byte("updated4"))
+	assert.Equal(t, []string{"updated1", "value4"}, values, "Values stored after the snapshot should be released")
+
+	d.Clear()
+	assert.ElementsMatch(t, []string{"key4"}, keys, "Clear should only release the keys stored after the snapshot")
+	assert.ElementsMatch(t, []string{"updated1", "value4", "again1", "updated4"}, values, "Clear should only release the values stored after the snapshot")
+
+	for i := 1; i <= 3; i++ {
+		assert.Equal(t, []byte(fmt.Sprintf("value%d", i)), snapshot.Get(fmt.Sprintf("key%d", i)), "Snapshot value of key%d should not be wiped", i)
+	}
+}
+
+func TestDictTypeDestructorsAfterSnapshotRelease(t *testing.T) {
+	var keys, values []string
+	d := NewDictWithType(&DictType[string, string]{
+		HashFunction:  hashing.NewSip24Hasher(),
+		KeyDestructor: func(key string) { keys = append(keys, key) },
+		ValDestructor: func(value string) { values = append(values, value) },
+	}).(*Dict[string, string])
+	for i := 1; i <= 3; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+
+	snapshot := d.Snapshot()
+	d.Set("key1", "updated1")
+	assert.Empty(t, values, "Values held by the snapshot should not be released")
+
+	// Once the snapshot is released, its keys and values are released again
+	snapshot.Release()
+	d.Set("key1", "again1")
+	d.Set("key2", "updated2")
+	assert.NoError(t, d.Delete("key3"))
+	assert.Equal(t, []string{"updated1", "value2", "value3"}, values, "Values should be released after the snapshot is released")
+	assert.Equal(t, []string{"key3"}, keys, "Keys should be released after the snapshot is released")
+
+	d.Clear()
+	assert.ElementsMatch(t, []string{"key3", "key1", "key2"}, keys, "Clear should release every key after the snapshot is released")
+}
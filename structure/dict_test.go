@@ -12,7 +12,7 @@ import (
 )
 
 func TestNewDict(t *testing.T) {
-	d := NewSipHashDict().(*Dict)
+	d := NewSipHashDict().(*Dict[string, string])
 	assert.NotNil(t, d, "Failed to create a new dictionary")
 	assert.Equal(t, 2, len(d.hashTables), "Missing two hashtables")
 	assert.NotNil(t, d.mainTable(), "Failed to get the main table")
@@ -21,44 +21,47 @@ func TestNewDict(t *testing.T) {
 }
 
 func TestMainTable(t *testing.T) {
-	d := &Dict{}
+	d := &Dict[string, string]{}
 	assert.Nil(t, d.mainTable(), "mainTable should be nil when hashTables is empty")
-	d.hashTables[0] = NewHashTable(0)
+	d.hashTables[0] = NewHashTable[string, string](0)
 	assert.NotNil(t, d.mainTable(), "mainTable should not be nil")
 }
 
 func TestRehashingTable(t *testing.T) {
-	d := &Dict{}
+	d := &Dict[string, string]{}
 	assert.Nil(t, d.rehashingTable(), "rehashingTable should be nil when hashTables is empty")
-	d.hashTables[1] = NewHashTable(0)
+	d.hashTables[1] = NewHashTable[string, string](0)
 	assert.NotNil(t, d.rehashingTable(), "rehashingTable should not be nil")
 }
 
-func TestKeyIndex(t *testing.T) {
-	d := NewSipHashDict().(*Dict)
-	d.mainTable().table = make([]*DictEntry, 4)
-	d.mainTable().table[0] = NewDictEntry("mango", "")
-	d.mainTable().table[0].next = NewDictEntry("orange", "")
-
-	var hexKey []byte
-	hex.Encode([]byte("banana"), hexKey)
-
-	key0, key1 := hashing.Split([16]byte{0, 1, 2, 3, 4})
-	d.hasher = &hashing.Sip24Hasher{
-		Key0: key0,
-		Key1: key1,
-	}
-
-	index := d.keyIndex(string(hexKey))
-	assert.Equal(t, 2, index, "Unexpected index for nonexistent key")
-
-	fmt.Printf("%v", d.hashTables)
-	index = d.keyIndex("orange")
-	assert.Equal(t, -1, index, "Unexpected index for nonexistent key")
+func TestFindPositionForInsert(t *testing.T) {
+	d := NewSipHashDictWithSeed([16]byte{0, 1, 2, 3, 4}).(*Dict[string, string])
+
+	entry, hashTable, index := d.findPositionForInsert("mango")
+	assert.Nil(t, entry, "Unexpected entry for nonexistent key")
+	assert.Equal(t, d.mainTable(), hashTable, "New keys should go to the main table")
+	assert.Equal(t, d.hasher.Digest("mango")&d.mainTable().sizemask, index, "Unexpected index for nonexistent key")
+	d.insertAt(hashTable, index, "mango", "1")
+
+	entry, hashTable, _ = d.findPositionForInsert("mango")
+	assert.NotNil(t, entry, "Existing key should be found")
+	assert.Nil(t, hashTable, "No position should be returned for an existing key")
+	assert.Equal(t, "1", entry.value, "Unexpected value for mango")
+
+	// While rehashing, new keys go to the rehashing table
+	d.expand(64)
+	d.pauseRehash++
+	entry, hashTable, index = d.findPositionForInsert("orange")
+	assert.Nil(t, entry, "Unexpected entry for nonexistent key")
+	assert.Equal(t, d.rehashingTable(), hashTable, "New keys should go to the rehashing table")
+	assert.Equal(t, d.hasher.Digest("orange")&d.rehashingTable().sizemask, index, "Unexpected index for nonexistent key")
+
+	entry, _, _ = d.findPositionForInsert("mango")
+	assert.NotNil(t, entry, "Keys of the main table should be found while rehashing")
 }
 
 func TestExpandIfNeeded(t *testing.T) {
-	d := NewSipHashDict().(*Dict)
+	d := NewSipHashDict().(*Dict[string, string])
 	// Test when rehashing is false and mainTable is nil
 	d.expandIfNeeded()
 	assert.NotNil(t, d.mainTable(), "mainTable should not be nil after first expansion")
@@ -79,7 +82,7 @@ func TestExpandIfNeeded(t *testing.T) {
 }
 
 func TestExpand(t *testing.T) {
-	d := NewSipHashDict().(*Dict)
+	d := NewSipHashDict().(*Dict[string, string])
 
 	// Test when rehashing is true
 	d.rehashidx = 0
@@ -179,7 +182,7 @@ func TestSipHashDigestWithKnownVectors(t *testing.T) {
 }
 
 func TestAdd(t *testing.T) {
-	dictionary := NewSipHashDict().(*Dict)
+	dictionary := NewSipHashDict().(*Dict[string, string])
 
 	key1 := "keyTest"
 	value1 := "123"
@@ -192,7 +195,8 @@ func TestAdd(t *testing.T) {
 
 	err = dictionary.add(key1, "newValue")
 	assert.Error(t, err, "There should be an error for adding an existing key")
-	assert.EqualError(t, err, fmt.Sprintf(`unexpectedly found an entry with the same key when trying to add #{ %s } / #{ %s }`, key1, "newValue"), "Unexpected error")
+	assert.ErrorIs(t, err, ErrKeyExists, "Unexpected error")
+	assert.EqualError(t, err, fmt.Sprintf(`cannot add key %s: key already exists`, key1), "Unexpected error")
 
 	entry = dictionary.getEntry(key1)
 	assert.NotNil(t, entry, "Key %s was not added correctly", key1)
@@ -212,7 +216,10 @@ func TestAdd(t *testing.T) {
 }
 
 func TestRehash(t *testing.T) {
-	d := NewSipHashDict().(*Dict)
+	if DICT_DEBUG {
+		t.Skip("the dictionaries are built by hand in invalid states")
+	}
+	d := NewSipHashDict().(*Dict[string, string])
 
 	//Not rehashing
 	d.rehash(1)
@@ -224,9 +231,9 @@ func TestRehash(t *testing.T) {
 	assert.Equal(t, d.rehashidx, -1)
 
 	//Rehashing last element from rehashing table
-	d = NewSipHashDict().(*Dict)
-	d.rehashingTable().table = make([]*DictEntry, 1)
-	d.rehashingTable().table[0] = NewDictEntry("key-test", "value-test")
+	d = NewSipHashDict().(*Dict[string, string])
+	d.rehashingTable().table = make([]*DictEntry[string, string], 1)
+	d.rehashingTable().table[0] = NewDictEntry[string, string]("key-test", "value-test")
 	d.add("key1", "value1")
 	d.rehashidx = 0
 	d.rehash(1)
@@ -239,7 +246,7 @@ func TestRehash(t *testing.T) {
 }
 
 func TestRehashing(t *testing.T) {
-	d := NewSipHashDict().(*Dict)
+	d := NewSipHashDict().(*Dict[string, string])
 
 	assert.False(t, d.isRehashing(), "Unexpected rehashing status when rehashing is false")
 	d.rehashidx = 0
@@ -248,7 +255,7 @@ func TestRehashing(t *testing.T) {
 
 func TestGetEntry(t *testing.T) {
 	// Test getEntry method
-	d := NewSipHashDict().(*Dict)
+	d := NewSipHashDict().(*Dict[string, string])
 
 	// Test when both tables are empty
 	entry := d.getEntry("nonexistent_key")
@@ -270,7 +277,7 @@ func TestGetEntry(t *testing.T) {
 
 func TestDelete(t *testing.T) {
 	// Test delete method
-	d := NewSipHashDict().(*Dict)
+	d := NewSipHashDict().(*Dict[string, string])
 
 	// Test deleting a key that does not exist
 	deletedEntry := d.delete("nonexistent_key")
@@ -301,6 +308,23 @@ func TestGet(t *testing.T) {
 	assert.Equal(t, "value1", value, "Unexpected value for key1")
 }
 
+func TestLookup(t *testing.T) {
+	d := NewSipHashDict()
+
+	_, found := d.Lookup("nonexistent_key")
+	assert.False(t, found, "Nonexistent key should not be found")
+
+	assert.NoError(t, d.Set("empty", ""))
+	value, found := d.Lookup("empty")
+	assert.True(t, found, "Key holding the zero value should be found")
+	assert.Equal(t, "", value, "Unexpected value for empty")
+
+	assert.NoError(t, d.Delete("empty"))
+	_, found = d.Lookup("empty")
+	assert.False(t, found, "Deleted key should not be found")
+	assert.ErrorIs(t, d.Delete("empty"), ErrKeyNotFound, "Deleting a missing key should return ErrKeyNotFound")
+}
+
 func TestSet(t *testing.T) {
 	// // Test Set method
 	d := NewSipHashDict()
@@ -392,3 +416,325 @@ func TestGetAllItems_Rehashing(t *testing.T) {
 		assert.Equal(t, fmt.Sprintf("value%d", i), value, "Expected correct value for key %s", key)
 	}
 }
+
+func TestNewDictWithCustomKeyType(t *testing.T) {
+	hasher := hashing.HasherFunc[int](func(key int) uint64 {
+		return uint64(key)
+	})
+	d := NewDict[int, []byte](hasher)
+
+	// Insert enough keys to go through at least one incremental rehash
+	for i := 0; i < 100; i++ {
+		err := d.Set(i, []byte(fmt.Sprintf("value%d", i)))
+		assert.NoError(t, err, "Unexpected error setting key %d", i)
+	}
+
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, []byte(fmt.Sprintf("value%d", i)), d.Get(i), "Unexpected value for key %d", i)
+	}
+
+	assert.Nil(t, d.Get(1000), "Expected zero value for a nonexistent key")
+
+	assert.NoError(t, d.Delete(42), "Unexpected error deleting key 42")
+	assert.Nil(t, d.Get(42), "Expected zero value for key 42 after delete")
+	assert.Len(t, d.GetAllItems(), 99, "Unexpected number of items after delete")
+}
+
+func TestScan_EmptyDict(t *testing.T) {
+	d := NewSipHashDict()
+
+	called := false
+	cursor := d.Scan(0, func(key, value string) { called = true })
+	assert.Equal(t, uint64(0), cursor, "Scan of an empty dictionary should complete immediately")
+	assert.False(t, called, "Scan of an empty dictionary should not visit any element")
+}
+
+func TestScan_AllItems(t *testing.T) {
+	d := NewSipHashDict()
+	for i := 0; i < 100; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+
+	items := make(map[string]string)
+	cursor := uint64(0)
+	for {
+		cursor = d.Scan(cursor, func(key, value string) { items[key] = value })
+		if cursor == 0 {
+			break
+		}
+	}
+
+	assert.Equal(t, d.GetAllItems(), items, "Scan should return every element of the dictionary")
+}
+
+func TestScan_DuringRehashing(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	for i := 0; i < 64; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+
+	seen := make(map[string]bool)
+	cursor := uint64(0)
+	added := 0
+	for {
+		cursor = d.Scan(cursor, func(key, value string) { seen[key] = true })
+		if cursor == 0 {
+			break
+		}
+		// Grow the dictionary between calls so that expand and rehash change the table sizes
+		for j := 0; j < 10; j++ {
+			d.Set(fmt.Sprintf("new%d", added), "value")
+			added++
+		}
+	}
+
+	for i := 0; i < 64; i++ {
+		key := fmt.Sprintf("key%d", i)
+		assert.True(t, seen[key], "Expected key %s to be returned by Scan", key)
+	}
+}
+
+func TestNextCursor(t *testing.T) {
+	// With a table of 8 buckets the cursor visits the buckets in reverse binary order
+	expected := []uint64{4, 2, 6, 1, 5, 3, 7, 0}
+	cursor := uint64(0)
+	for _, want := range expected {
+		cursor = nextCursor(cursor, 7)
+		assert.Equal(t, want, cursor&7, "Unexpected cursor")
+	}
+}
+
+// completeRehashing drives the incremental rehashing until it is complete.
+func completeRehashing[K comparable, V any](d *Dict[K, V]) {
+	for d.isRehashing() {
+		d.rehash(100)
+	}
+}
+
+func TestShrinkIfNeeded(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	for i := 0; i < 1000; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	completeRehashing(d)
+	assert.Equal(t, int64(1024), d.mainTable().size, "Unexpected size after inserting 1000 keys")
+
+	// Deleting 90% of the keys makes the table fill fall under 1/8
+	for i := 0; i < 900; i++ {
+		assert.NoError(t, d.Delete(fmt.Sprintf("key%d", i)))
+	}
+	completeRehashing(d)
+	assert.True(t, d.mainTable().size < 1024, "Expected the table to shrink after mass deletes")
+	assert.True(t, d.mainTable().size >= d.mainTable().used, "Shrunk table should still contain all the keys")
+
+	for i := 900; i < 1000; i++ {
+		key := fmt.Sprintf("key%d", i)
+		assert.Equal(t, fmt.Sprintf("value%d", i), d.Get(key), "Unexpected value for key %s after shrinking", key)
+	}
+
+	// Deleting all the keys shrinks the table to the initial size
+	for i := 900; i < 1000; i++ {
+		assert.NoError(t, d.Delete(fmt.Sprintf("key%d", i)))
+	}
+	completeRehashing(d)
+	assert.Equal(t, INITIAL_SIZE, d.mainTable().size, "Expected the table to shrink to the initial size")
+	assert.Equal(t, int64(0), d.mainTable().used, "Expected an empty table")
+}
+
+func TestShrinkIfNeeded_DeletesDuringShrink(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	for i := 0; i < 1000; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	completeRehashing(d)
+
+	// The table shrinks to 128 buckets when the fill falls under 1/8
+	for i := 0; i < 872; i++ {
+		assert.NoError(t, d.Delete(fmt.Sprintf("key%d", i)))
+	}
+	assert.True(t, d.isRehashing(), "Expected the table to start shrinking")
+	assert.Equal(t, int64(128), d.rehashingTable().size, "Unexpected size of the shrunk table")
+
+	// The deletes performed while shrinking bring the fill of the shrunk table under 1/8
+	d.pauseRehash++
+	for i := 872; i < 990; i++ {
+		assert.NoError(t, d.Delete(fmt.Sprintf("key%d", i)))
+	}
+	d.pauseRehash--
+
+	d.rehash(1024)
+	assert.Equal(t, int64(128), d.mainTable().size, "Expected the first shrink to be complete")
+	assert.True(t, d.isRehashing(), "Expected a second shrink to be scheduled")
+	assert.Equal(t, int64(16), d.rehashingTable().size, "Unexpected size of the table after the second shrink")
+
+	completeRehashing(d)
+	assert.Equal(t, int64(16), d.mainTable().size, "Unexpected size after the second shrink")
+	for i := 990; i < 1000; i++ {
+		key := fmt.Sprintf("key%d", i)
+		assert.Equal(t, fmt.Sprintf("value%d", i), d.Get(key), "Unexpected value for key %s after shrinking", key)
+	}
+}
+
+func TestResize(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	for i := 0; i < 100; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+
+	completeRehashing(d)
+
+	// Resize is refused while rehashing
+	d.expand(256)
+	assert.True(t, d.isRehashing(), "Expected the dictionary to be rehashing")
+	assert.EqualError(t, d.Resize(), `cannot resize while rehashing`)
+	completeRehashing(d)
+	for i := 0; i < 60; i++ {
+		d.Delete(fmt.Sprintf("key%d", i))
+	}
+	completeRehashing(d)
+	assert.Equal(t, int64(256), d.mainTable().size, "Table should not shrink while filled for more than 1/8")
+
+	assert.NoError(t, d.Resize())
+	assert.True(t, d.isRehashing(), "Resize should start an incremental rehashing")
+	completeRehashing(d)
+	assert.Equal(t, int64(64), d.mainTable().size, "Resize should compact to the minimal power of two")
+
+	for i := 60; i < 100; i++ {
+		key := fmt.Sprintf("key%d", i)
+		assert.Equal(t, fmt.Sprintf("value%d", i), d.Get(key), "Unexpected value for key %s after resize", key)
+	}
+}
+
+func TestRehashSteps(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	assert.False(t, d.RehashSteps(1), "Unexpected rehashing on an empty dictionary")
+
+	for i := 0; i < 100; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	completeRehashing(d)
+	d.expand(1024)
+
+	// Rehashing is paused while a safe iterator is alive
+	it := d.SafeIterator()
+	it.Next()
+	assert.True(t, d.RehashSteps(1000), "Rehashing should be paused by a safe iterator")
+	assert.Equal(t, 0, d.rehashidx, "Rehashing should be paused by a safe iterator")
+	it.Release()
+
+	assert.True(t, d.RehashSteps(1), "Expected entries still to migrate after one step")
+	assert.False(t, d.RehashSteps(1000), "Expected the rehashing to be complete")
+	assert.False(t, d.isRehashing(), "Expected the rehashing to be complete")
+	assert.Equal(t, int64(1024), d.mainTable().size, "Unexpected size after rehashing")
+	assert.Equal(t, int64(100), d.mainTable().used, "Unexpected number of entries after rehashing")
+}
+
+func TestRehashMilliseconds(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	assert.Equal(t, 0, d.RehashMilliseconds(1), "Unexpected rehashing on an empty dictionary")
+
+	for i := 0; i < 10000; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	completeRehashing(d)
+	d.expand(1 << 16)
+	assert.True(t, d.isRehashing(), "Expected the dictionary to be rehashing")
+
+	rehashes := d.RehashMilliseconds(1000)
+	assert.True(t, rehashes > 0, "Expected some rehashing steps")
+	assert.False(t, d.isRehashing(), "Expected the rehashing to be complete within the budget")
+	assert.Len(t, d.GetAllItems(), 10000, "Unexpected number of entries after rehashing")
+}
+
+func TestRehashMilliseconds_CountsLastBatch(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	for i := 0; i < 10; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	completeRehashing(d)
+	d.expand(64)
+	buckets := 0
+	for _, entry := range d.mainTable().table {
+		if entry != nil {
+			buckets++
+		}
+	}
+
+	// The rehashing completes within the first batch of 100 steps
+	assert.True(t, d.RehashMilliseconds(1000) >= buckets, "Every migrated bucket should be counted")
+	assert.False(t, d.isRehashing(), "Expected the rehashing to be complete")
+}
+
+func TestCaseInsensitiveDict(t *testing.T) {
+	d := NewCaseInsensitiveDict()
+
+	assert.NoError(t, d.Set("foo", "value1"))
+	assert.Equal(t, "value1", d.Get("FOO"), "Get should ignore the case of the key")
+	assert.Equal(t, "value1", d.Get("fOo"), "Get should ignore the case of the key")
+
+	// Updating with a different casing keeps the original key
+	assert.NoError(t, d.Set("FOO", "value2"))
+	assert.Equal(t, map[string]string{"foo": "value2"}, d.GetAllItems(), "Enumeration should preserve the original casing")
+
+	for i := 0; i < 100; i++ {
+		d.Set(fmt.Sprintf("Key%d", i), fmt.Sprintf("value%d", i))
+	}
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, fmt.Sprintf("value%d", i), d.Get(fmt.Sprintf("KEY%d", i)), "Unexpected value for key KEY%d", i)
+	}
+
+	assert.NoError(t, d.Delete("Foo"), "Delete should ignore the case of the key")
+	assert.Equal(t, "", d.Get("foo"), "Unexpected value for foo after delete")
+	assert.Len(t, d.GetAllItems(), 100, "Unexpected number of items after delete")
+}
+
+func TestNewSipHashDictWithSeed(t *testing.T) {
+	seed := [16]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+	first := NewSipHashDictWithSeed(seed).(*Dict[string, string])
+	second := NewSipHashDictWithSeed(seed).(*Dict[string, string])
+
+	readSeed, ok := first.Seed()
+	assert.True(t, ok, "Expected a seeded hasher")
+	assert.Equal(t, seed, readSeed, "Seed() should return the seed the Dict was built with")
+
+	// Dicts with the same seed have the same bucket layout
+	for i := 0; i < 100; i++ {
+		first.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+		second.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	for i, hashTable := range first.hashTables {
+		assert.Equal(t, len(hashTable.table), len(second.hashTables[i].table), "Unexpected table size")
+		for index, entry := range hashTable.table {
+			other := second.hashTables[i].table[index]
+			for entry != nil && other != nil {
+				assert.Equal(t, entry.key, other.key, "Expected the same bucket layout")
+				entry, other = entry.next, other.next
+			}
+			assert.True(t, entry == nil && other == nil, "Expected the same bucket layout")
+		}
+	}
+}
+
+func TestSeedPerDict(t *testing.T) {
+	first, _ := NewSipHashDict().(*Dict[string, string]).Seed()
+	second, _ := NewSipHashDict().(*Dict[string, string]).Seed()
+	assert.NotEqual(t, first, second, "Each Dict should have its own random seed")
+
+	d := NewDict[int, int](hashing.HasherFunc[int](func(key int) uint64 { return uint64(key) })).(*Dict[int, int])
+	_, ok := d.Seed()
+	assert.False(t, ok, "Unexpected seed for an unseeded hasher")
+}
+
+func TestLen(t *testing.T) {
+	d := NewSipHashDict()
+	assert.Equal(t, 0, d.Len(), "Unexpected length of an empty dictionary")
+
+	for i := 0; i < 100; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	d.Set("key1", "updated")
+	assert.Equal(t, 100, d.Len(), "Unexpected length, also counting the entries of the rehashing table")
+
+	d.Delete("key1")
+	assert.Equal(t, 99, d.Len(), "Unexpected length after delete")
+}
@@ -0,0 +1,12 @@
+package structure
+
+import "errors"
+
+var (
+	// ErrKeyNotFound is returned when an operation requires a key that is not in the dictionary.
+	ErrKeyNotFound = errors.New(`entry not found`)
+	// ErrKeyExists is returned when adding a key that is already in the dictionary.
+	ErrKeyExists = errors.New(`key already exists`)
+	// ErrInvalidDict is wrapped by the errors of Dict.Validate.
+	ErrInvalidDict = errors.New(`invalid dictionary`)
+)
@@ -0,0 +1,143 @@
+package structure
+
+import (
+	"testing"
+
+	"github.com/dmarro89/go-redis-hashtable/hashing"
+)
+
+// Operations replayed by FuzzDict, each one encoded by an opcode byte and an argument byte.
+const (
+	fuzzSet = iota
+	fuzzLookup
+	fuzzDelete
+	fuzzRehashSteps
+	fuzzSetIfAbsent
+	fuzzGetAndDelete
+	fuzzUpsert
+	fuzzExpand
+	fuzzOps
+)
+
+// newFuzzHasher returns a deterministic hasher keeping only the low bits bits of a scrambled
+// key, so that the fuzzer controls how many keys collide in the same bucket.
+func newFuzzHasher(bits uint8) hashing.IHasher[uint8] {
+	mask := uint64(1)<<(bits%9) - 1
+	return hashing.HasherFunc[uint8](func(key uint8) uint64 {
+		return (uint64(key) * 0x9E3779B97F4A7C15 >> 32) & mask
+	})
+}
+
+// fuzzDict is implemented by the dictionaries replayed by fuzzReplay.
+type fuzzDict interface {
+	IDict[uint8, int]
+	RehashSteps(n int) bool
+	Validate() error
+	expand(newSize int64)
+}
+
+// addFuzzSeeds adds the seed corpus shared by the fuzz targets.
+func addFuzzSeeds(f *testing.F) {
+	// No collisions, growth and shrink
+	f.Add(uint8(8), []byte{fuzzSet, 1, fuzzSet, 2, fuzzSet, 3, fuzzSet, 4, fuzzSet, 5, fuzzDelete, 1, fuzzDelete, 2, fuzzLookup, 3})
+	// Every key in the same bucket
+	f.Add(uint8(0), []byte{fuzzSet, 1, fuzzSet, 2, fuzzSet, 3, fuzzDelete, 2, fuzzUpsert, 3, fuzzGetAndDelete, 1, fuzzLookup, 3})
+	// Long chains in the same bucket, filling the child buckets of a BucketDict
+	f.Add(uint8(0), []byte{fuzzSet, 1, fuzzSet, 2, fuzzSet, 3, fuzzSet, 4, fuzzSet, 5, fuzzSet, 6, fuzzSet, 7, fuzzSet, 8, fuzzDelete, 2, fuzzUpsert, 3, fuzzGetAndDelete, 7, fuzzLookup, 8})
+	// Operations in the middle of a rehashing
+	f.Add(uint8(2), []byte{fuzzSet, 1, fuzzSet, 2, fuzzSet, 3, fuzzSet, 4, fuzzExpand, 64, fuzzRehashSteps, 1, fuzzSetIfAbsent, 5, fuzzDelete, 3, fuzzExpand, 0, fuzzLookup, 4})
+}
+
+// FuzzDict replays arbitrary sequences of operations against a Dict and a Go map used as
+// a model, failing on any divergence or broken invariant.
+func FuzzDict(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, bits uint8, ops []byte) {
+		fuzzReplay(t, NewDict[uint8, int](newFuzzHasher(bits)).(*Dict[uint8, int]), ops)
+	})
+}
+
+// newBucketFuzzHasher is newFuzzHasher keeping the top byte of the scrambled key too,
+// so that the hash fragments of a BucketDict differ between the colliding keys.
+func newBucketFuzzHasher(bits uint8) hashing.IHasher[uint8] {
+	mask := uint64(1)<<(bits%9) - 1
+	return hashing.HasherFunc[uint8](func(key uint8) uint64 {
+		scrambled := uint64(key) * 0x9E3779B97F4A7C15
+		return (scrambled>>32)&mask | scrambled&(0xFF<<56)
+	})
+}
+
+// FuzzBucketDict is FuzzDict for a BucketDict.
+func FuzzBucketDict(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, bits uint8, ops []byte) {
+		fuzzReplay(t, NewBucketDict[uint8, int](newBucketFuzzHasher(bits)).(*BucketDict[uint8, int]), ops)
+	})
+}
+
+// fuzzReplay replays the operations encoded by ops against d and a Go map used as a model.
+func fuzzReplay(t *testing.T, d fuzzDict, ops []byte) {
+	model := make(map[uint8]int)
+
+	for i := 0; i+1 < len(ops); i += 2 {
+		key := ops[i+1]
+		switch ops[i] % fuzzOps {
+		case fuzzSet:
+			d.Set(key, i)
+			model[key] = i
+		case fuzzLookup:
+			value, found := d.Lookup(key)
+			expected, expectedFound := model[key]
+			if value != expected || found != expectedFound {
+				t.Fatalf("op %d: Lookup(%d) = %d, %v, expected %d, %v", i, key, value, found, expected, expectedFound)
+			}
+		case fuzzDelete:
+			_, expectedFound := model[key]
+			if err := d.Delete(key); (err == nil) != expectedFound {
+				t.Fatalf("op %d: Delete(%d) = %v, expected found %v", i, key, err, expectedFound)
+			}
+			delete(model, key)
+		case fuzzRehashSteps:
+			d.RehashSteps(int(key % 8))
+		case fuzzSetIfAbsent:
+			_, found := model[key]
+			if d.SetIfAbsent(key, i) == found {
+				t.Fatalf("op %d: SetIfAbsent(%d) = %v, expected %v", i, key, found, !found)
+			}
+			if !found {
+				model[key] = i
+			}
+		case fuzzGetAndDelete:
+			value, found := d.GetAndDelete(key)
+			expected, expectedFound := model[key]
+			if value != expected || found != expectedFound {
+				t.Fatalf("op %d: GetAndDelete(%d) = %d, %v, expected %d, %v", i, key, value, found, expected, expectedFound)
+			}
+			delete(model, key)
+		case fuzzUpsert:
+			d.Upsert(key, func(old int, exists bool) int { return old + 1 })
+			model[key]++
+		case fuzzExpand:
+			// Resizes to any size, forcing rehashings that grow or shrink the table
+			d.expand(int64(key))
+		}
+
+		if err := d.Validate(); err != nil {
+			t.Fatalf("op %d: %v", i, err)
+		}
+	}
+
+	if d.Len() != len(model) {
+		t.Fatalf("Len() = %d, expected %d", d.Len(), len(model))
+	}
+
+	scanned := make(map[uint8]int)
+	for cursor := d.Scan(0, func(key uint8, value int) { scanned[key] = value }); cursor != 0; {
+		cursor = d.Scan(cursor, func(key uint8, value int) { scanned[key] = value })
+	}
+	for key, expected := range model {
+		if value, found := scanned[key]; !found || value != expected {
+			t.Fatalf("Scan returned %d, %v for key %d, expected %d", value, found, key, expected)
+		}
+	}
+}
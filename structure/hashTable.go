@@ -1,10 +1,12 @@
 package structure
 
-type HashTable struct {
-	table    []*DictEntry
+type HashTable[K comparable, V any] struct {
+	table    []*DictEntry[K, V]
 	size     int64
 	sizemask uint64
 	used     int64
+	// shared is true when the bucket array is shared with a Snapshot
+	shared bool
 }
 
 // NewHashTable creates a new HashTable with the specified size.
@@ -14,15 +16,15 @@ type HashTable struct {
 //
 // Returns:
 // - *HashTable: a pointer to the newly created HashTable.
-func NewHashTable(size int64) *HashTable {
+func NewHashTable[K comparable, V any](size int64) *HashTable[K, V] {
 	var sizemask uint64
-	table := []*DictEntry{}
+	table := []*DictEntry[K, V]{}
 	if size > 0 {
-		table = make([]*DictEntry, size)
+		table = make([]*DictEntry[K, V], size)
 		sizemask = uint64(size - 1)
 	}
 
-	return &HashTable{
+	return &HashTable[K, V]{
 		table:    table,
 		size:     size,
 		sizemask: sizemask,
@@ -32,6 +34,6 @@ func NewHashTable(size int64) *HashTable {
 // empty checks if the hash table is empty.
 //
 // Returns true if the hash table is empty, false otherwise.
-func (ht *HashTable) empty() bool {
+func (ht *HashTable[K, V]) empty() bool {
 	return ht.size == 0
 }
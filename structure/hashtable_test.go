@@ -8,7 +8,7 @@ import (
 
 func TestNewHashTable(t *testing.T) {
 	size := int64(10)
-	ht := NewHashTable(size)
+	ht := NewHashTable[string, string](size)
 
 	assert.Equal(t, size, ht.size, "Expected size %d, but got %d", size, ht.size)
 	assert.Equal(t, uint64(size-1), ht.sizemask, "Expected sizemask %d, but got %d", size-1, ht.sizemask)
@@ -18,7 +18,7 @@ func TestNewHashTable(t *testing.T) {
 
 func TestEmptyHashTable(t *testing.T) {
 	size := int64(0)
-	ht := NewHashTable(0)
+	ht := NewHashTable[string, string](0)
 
 	assert.Equal(t, size, ht.size, "Expected size %d, but got %d", size, ht.size)
 	assert.Equal(t, uint64(size), ht.sizemask, "Expected sizemask %d, but got %d", size, ht.sizemask)
@@ -0,0 +1,161 @@
+package structure
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// Iterator walks the entries of both hash tables of a Dict, like Redis dictIterator.
+//
+// A safe iterator pauses incremental rehashing while it is alive, so the dictionary can be
+// modified with Set and Delete during the iteration. An unsafe iterator only allows reads:
+// it records a fingerprint of the dictionary when the iteration starts, and Release returns
+// an error if the dictionary was modified in the meantime.
+type Iterator[K comparable, V any] struct {
+	d           *Dict[K, V]
+	table       int
+	index       int64
+	safe        bool
+	started     bool
+	done        bool
+	entry       *DictEntry[K, V]
+	nextEntry   *DictEntry[K, V]
+	fingerprint uint64
+}
+
+// Iterator returns a new unsafe iterator over the dictionary.
+//
+// No parameters.
+// Returns a pointer to Iterator.
+func (d *Dict[K, V]) Iterator() *Iterator[K, V] {
+	return &Iterator[K, V]{
+		d:     d,
+		index: -1,
+	}
+}
+
+// SafeIterator returns a new safe iterator over the dictionary.
+//
+// No parameters.
+// Returns a pointer to Iterator.
+func (d *Dict[K, V]) SafeIterator() *Iterator[K, V] {
+	it := d.Iterator()
+	it.safe = true
+	return it
+}
+
+// Next advances the iterator to the next entry of the dictionary.
+//
+// No parameters.
+// Returns true if the iterator points to an entry, false when the iteration is complete.
+func (it *Iterator[K, V]) Next() bool {
+	if it.done {
+		return false
+	}
+
+	for {
+		if it.entry == nil {
+			if !it.started {
+				it.started = true
+				if it.safe {
+					it.d.pauseRehash++
+				} else {
+					it.fingerprint = it.d.fingerprint()
+				}
+			}
+
+			it.index++
+			if it.index >= int64(len(it.d.hashTables[it.table].table)) {
+				if it.d.isRehashing() && it.table == 0 {
+					it.table++
+					it.index = 0
+				} else {
+					it.done = true
+					return false
+				}
+			}
+
+			it.entry = it.d.hashTables[it.table].table[it.index]
+		} else {
+			it.entry = it.nextEntry
+		}
+
+		if it.entry != nil {
+			// Save the next entry, the caller may delete the one we are returning
+			it.nextEntry = it.entry.next
+			return true
+		}
+	}
+}
+
+// Key returns the key of the entry the iterator points to.
+//
+// No parameters.
+// Returns the key of the current entry.
+func (it *Iterator[K, V]) Key() K {
+	return it.entry.key
+}
+
+// Value returns the value of the entry the iterator points to.
+//
+// No parameters.
+// Returns the value of the current entry.
+func (it *Iterator[K, V]) Value() V {
+	return it.entry.value
+}
+
+// Release releases the iterator, resuming incremental rehashing for a safe iterator.
+//
+// No parameters.
+//
+// Returns:
+// - error: if the dictionary was modified during the iteration of an unsafe iterator.
+func (it *Iterator[K, V]) Release() error {
+	if !it.started {
+		return nil
+	}
+	it.started = false
+	it.done = true
+
+	if it.safe {
+		it.d.pauseRehash--
+		return nil
+	}
+
+	if it.fingerprint != it.d.fingerprint() {
+		return fmt.Errorf(`dictionary modified during unsafe iteration`)
+	}
+	return nil
+}
+
+// fingerprint returns a 64-bit number representing the state of the dictionary at a given time.
+// It combines the bucket array pointer, the size and the number of used entries of both hash tables,
+// so that a change in any of them is detected by an unsafe iterator.
+//
+// No parameters.
+// Returns the fingerprint as uint64.
+func (d *Dict[K, V]) fingerprint() uint64 {
+	integers := [6]uint64{
+		tablePointer(d.mainTable()), uint64(d.mainTable().size), uint64(d.mainTable().used),
+		tablePointer(d.rehashingTable()), uint64(d.rehashingTable().size), uint64(d.rehashingTable().used),
+	}
+
+	// Tomas Wang's 64 bit integer hash, applied to the running hash plus each integer
+	var hash uint64
+	for _, integer := range integers {
+		hash += integer
+		hash = (^hash) + (hash << 21)
+		hash = hash ^ (hash >> 24)
+		hash = (hash + (hash << 3)) + (hash << 8)
+		hash = hash ^ (hash >> 14)
+		hash = (hash + (hash << 2)) + (hash << 4)
+		hash = hash ^ (hash >> 28)
+		hash = hash + (hash << 31)
+	}
+	return hash
+}
+
+// tablePointer returns the address of the bucket array of the given hash table.
+func tablePointer[K comparable, V any](ht *HashTable[K, V]) uint64 {
+	return uint64(uintptr(unsafe.Pointer(unsafe.SliceData(ht.table))))
+}
@@ -0,0 +1,102 @@
+package structure
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIterator_EmptyDict(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+
+	it := d.Iterator()
+	assert.False(t, it.Next(), "Unexpected entry in an empty dictionary")
+	assert.False(t, it.Next(), "Next should keep returning false once the iteration is complete")
+	assert.NoError(t, it.Release(), "Unexpected error releasing the iterator")
+}
+
+func TestIterator_AllItems(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	for i := 0; i < 100; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	for d.isRehashing() {
+		d.rehash(100)
+	}
+	d.expand(1024)
+	d.rehash(8)
+	assert.True(t, d.isRehashing(), "Expected the dictionary to be rehashing")
+
+	items := make(map[string]string)
+	it := d.Iterator()
+	for it.Next() {
+		_, exists := items[it.Key()]
+		assert.False(t, exists, "Key %s returned twice", it.Key())
+		items[it.Key()] = it.Value()
+	}
+	assert.NoError(t, it.Release(), "Unexpected error releasing the iterator")
+	assert.Equal(t, d.GetAllItems(), items, "Iterator should return every element of the dictionary")
+}
+
+func TestIterator_UnsafeDetectsMutation(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	for i := 0; i < 10; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+
+	it := d.Iterator()
+	assert.True(t, it.Next(), "Expected an entry in the dictionary")
+	d.Set("another-key", "value")
+	assert.EqualError(t, it.Release(), `dictionary modified during unsafe iteration`)
+
+	// Overwriting a value and reading does not change the structure of the dictionary
+	it = d.Iterator()
+	for it.Next() {
+		d.Set(it.Key(), "updated")
+		d.Get(it.Key())
+	}
+	assert.NoError(t, it.Release(), "Unexpected error releasing the iterator")
+}
+
+func TestIterator_SafeAllowsMutation(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	for i := 0; i < 100; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	for d.isRehashing() {
+		d.rehash(100)
+	}
+	d.expand(1024)
+	d.rehash(1)
+	assert.True(t, d.isRehashing(), "Expected the dictionary to be rehashing")
+	rehashidx := d.rehashidx
+
+	it := d.SafeIterator()
+	count := 0
+	for it.Next() {
+		assert.NoError(t, d.Delete(it.Key()), "Unexpected error deleting key %s", it.Key())
+		count++
+	}
+	assert.Equal(t, rehashidx, d.rehashidx, "Rehashing should be paused while a safe iterator is alive")
+	assert.Equal(t, 1, d.pauseRehash, "Rehashing should be paused while a safe iterator is alive")
+	assert.NoError(t, it.Release(), "Unexpected error releasing the iterator")
+
+	assert.Equal(t, 0, d.pauseRehash, "Rehashing should be resumed after releasing a safe iterator")
+	assert.Equal(t, 100, count, "Safe iterator should return every element of the dictionary")
+	assert.Empty(t, d.GetAllItems(), "Expected every element to be deleted")
+}
+
+func TestFingerprint(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	d.Set("key1", "value1")
+
+	fingerprint := d.fingerprint()
+	assert.Equal(t, fingerprint, d.fingerprint(), "Fingerprint should be stable when the dictionary is unchanged")
+
+	d.Set("key1", "value2")
+	assert.Equal(t, fingerprint, d.fingerprint(), "Overwriting a value should not change the fingerprint")
+
+	d.Delete("key1")
+	assert.NotEqual(t, fingerprint, d.fingerprint(), "Deleting an entry should change the fingerprint")
+}
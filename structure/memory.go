@@ -0,0 +1,63 @@
+package structure
+
+import "unsafe"
+
+// MemoryUsage returns an estimate of the bytes used by the dictionary: the bucket arrays
+// of both tables, the DictEntry structs, and the bytes referenced by string and []byte
+// keys and values. Other indirect allocations of keys and values are not accounted.
+// It walks every entry, so it is O(n).
+//
+// No parameters.
+// Returns the estimated number of bytes.
+func (d *Dict[K, V]) MemoryUsage() int64 {
+	usage := int64(unsafe.Sizeof(*d)) + int64(unsafe.Sizeof((*DictEntry[K, V])(nil)))*d.bucketsCount()
+
+	for _, hashTable := range d.hashTables {
+		for _, entry := range hashTable.table {
+			for ; entry != nil; entry = entry.next {
+				usage += int64(unsafe.Sizeof(*entry)) + dataSize(entry.key) + dataSize(entry.value)
+			}
+		}
+	}
+
+	return usage
+}
+
+// SetMemoryLimit sets the maximum number of bytes, as measured by MemoryUsage, the dictionary
+// may use after growing its bucket array. An expansion that would exceed the limit is refused,
+// and the entries keep being chained in the current buckets until the load factor exceeds
+// FORCE_RESIZE_RATIO. The usage is measured once per table size: a refused expansion is not
+// measured again until the table is resized or the limit changes.
+//
+// Parameters:
+// - limit: the memory limit in bytes, or 0 for no limit.
+//
+// No return values.
+func (d *Dict[K, V]) SetMemoryLimit(limit int64) {
+	d.memoryLimit = limit
+	d.vetoedSize = 0
+}
+
+// bucketsCount returns the number of buckets allocated by both tables.
+func (d *Dict[K, V]) bucketsCount() int64 {
+	return int64(len(d.mainTable().table) + len(d.rehashingTable().table))
+}
+
+// dataSize returns the number of bytes referenced by a key or a value,
+// besides the bytes already accounted in the DictEntry.
+//
+// Parameters:
+// - data: the key or the value.
+//
+// Returns:
+// - int64: the referenced bytes of strings and byte slices, 0 for the other types.
+func dataSize(data any) int64 {
+	switch data := data.(type) {
+	case string:
+		return int64(len(data))
+	case []byte:
+		return int64(cap(data))
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,88 @@
+package structure
+
+import (
+	"fmt"
+	"testing"
+	"unsafe"
+
+	"github.com/dmarro89/go-redis-hashtable/hashing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryUsage(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	empty := d.MemoryUsage()
+	assert.Equal(t, int64(unsafe.Sizeof(*d)), empty, "Unexpected usage of an empty dictionary")
+
+	d.Set("key1", "value1")
+	entrySize := int64(unsafe.Sizeof(DictEntry[string, string]{}))
+	pointerSize := int64(unsafe.Sizeof((*DictEntry[string, string])(nil)))
+	assert.Equal(t, empty+INITIAL_SIZE*pointerSize+entrySize+int64(len("key1")+len("value1")), d.MemoryUsage(), "Unexpected usage with one entry")
+
+	// Both tables are accounted while rehashing
+	for i := 0; i < 100; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	d.expand(1024)
+	assert.True(t, d.isRehashing(), "Dictionary should be rehashing")
+	rehashing := d.MemoryUsage()
+	oldSize := d.mainTable().size
+	completeRehashing(d)
+	assert.Equal(t, rehashing-oldSize*pointerSize, d.MemoryUsage(), "The old bucket array should not be accounted after rehashing")
+
+	assert.NoError(t, d.Delete("key1"))
+	assert.Less(t, d.MemoryUsage(), rehashing, "Usage should decrease after delete")
+}
+
+func TestMemoryUsageBytes(t *testing.T) {
+	d := NewDict[int, []byte](hashing.HasherFunc[int](func(key int) uint64 { return uint64(key) })).(*Dict[int, []byte])
+	d.Set(1, make([]byte, 10, 100))
+
+	entrySize := int64(unsafe.Sizeof(DictEntry[int, []byte]{}))
+	pointerSize := int64(unsafe.Sizeof((*DictEntry[int, []byte])(nil)))
+	assert.Equal(t, int64(unsafe.Sizeof(*d))+INITIAL_SIZE*pointerSize+entrySize+100, d.MemoryUsage(), "The capacity of byte slices should be accounted")
+}
+
+func TestSetMemoryLimit(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	for i := 0; i < 64; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	completeRehashing(d)
+	size := d.mainTable().size
+
+	// Doubling the bucket array would exceed the limit
+	d.SetMemoryLimit(d.MemoryUsage() + size*int64(unsafe.Sizeof((*DictEntry[string, string])(nil))))
+	forced := int(size * FORCE_RESIZE_RATIO)
+	for i := 64; i < forced; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	assert.Equal(t, size, d.mainTable().size, "Expansion should be refused by the memory limit")
+	assert.False(t, d.isRehashing(), "Expansion should be refused by the memory limit")
+	assert.Equal(t, size, d.vetoedSize, "The refusal should be cached for the size of the table")
+	assert.Equal(t, forced, d.Len(), "Entries should still be added")
+	assert.Equal(t, fmt.Sprintf("value%d", forced-1), d.Get(fmt.Sprintf("key%d", forced-1)), "Unexpected value for the last key")
+
+	d.SetMemoryLimit(0)
+	d.Set("key-1", "value-1")
+	assert.True(t, d.isRehashing(), "Expansion should be allowed without limit")
+}
+
+func TestSetMemoryLimit_ForceResize(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	for i := 0; i < 64; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	completeRehashing(d)
+	size := d.mainTable().size
+
+	// The limit does not let the chains grow past FORCE_RESIZE_RATIO
+	d.SetMemoryLimit(d.MemoryUsage())
+	for i := 64; i <= int(size*FORCE_RESIZE_RATIO); i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	assert.True(t, d.isRehashing(), "Expansion should be forced past FORCE_RESIZE_RATIO")
+	completeRehashing(d)
+	assert.Greater(t, d.mainTable().size, size, "Expected the table to grow")
+	assert.Equal(t, int64(0), d.vetoedSize, "The cached refusal should be dropped when the table grows")
+}
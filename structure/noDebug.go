@@ -0,0 +1,9 @@
+//go:build !dictdebug
+
+package structure
+
+// DICT_DEBUG is true when built with the dictdebug tag.
+const DICT_DEBUG = false
+
+// debugValidate does nothing unless built with the dictdebug tag, see debug.go.
+func (d *Dict[K, V]) debugValidate() {}
@@ -0,0 +1,151 @@
+package structure
+
+import "math/rand/v2"
+
+const (
+	// GETFAIR_NUM_ENTRIES is the number of keys sampled by FairRandomKey.
+	GETFAIR_NUM_ENTRIES = 15
+)
+
+// RandomKey returns a random key of the dictionary, like Redis dictGetRandomKey.
+// A random non-empty bucket is picked among both tables, then a random entry of its chain.
+// Keys in long chains are less likely to be returned, see FairRandomKey.
+//
+// No parameters.
+//
+// Returns:
+// - K: the random key.
+// - bool: false if the dictionary is empty.
+func (d *Dict[K, V]) RandomKey() (K, bool) {
+	if d.mainTable().used == 0 && d.rehashingTable().used == 0 {
+		var zero K
+		return zero, false
+	}
+
+	if d.isRehashing() {
+		d.rehashStep()
+	}
+
+	var entry *DictEntry[K, V]
+	if d.isRehashing() {
+		// The buckets of the main table before rehashidx are empty, so they are skipped
+		mainSize := uint64(d.mainTable().size)
+		rehashidx := uint64(d.rehashidx)
+		for entry == nil {
+			index := rehashidx + rand.Uint64N(mainSize+uint64(d.rehashingTable().size)-rehashidx)
+			if index >= mainSize {
+				entry = d.rehashingTable().table[index-mainSize]
+			} else {
+				entry = d.mainTable().table[index]
+			}
+		}
+	} else {
+		for entry == nil {
+			entry = d.mainTable().table[rand.Uint64()&d.mainTable().sizemask]
+		}
+	}
+
+	// Pick a random element of the chain
+	length := 0
+	for current := entry; current != nil; current = current.next {
+		length++
+	}
+	for skip := rand.IntN(length); skip > 0; skip-- {
+		entry = entry.next
+	}
+
+	return entry.key, true
+}
+
+// SomeKeys returns up to count keys sampled from random locations of the dictionary,
+// like Redis dictGetSomeKeys. It is faster than calling RandomKey count times, since it
+// walks contiguous buckets from a random position, but the keys are not guaranteed to be
+// well distributed nor distinct, and fewer than count keys may be returned.
+//
+// Parameters:
+// - count: the maximum number of keys to return.
+//
+// Returns:
+// - []K: the sampled keys.
+func (d *Dict[K, V]) SomeKeys(count int) []K {
+	size := int(d.mainTable().used + d.rehashingTable().used)
+	if count > size {
+		count = size
+	}
+	if count <= 0 {
+		return nil
+	}
+
+	// Try to do a rehashing work proportional to count
+	for j := 0; j < count && d.isRehashing(); j++ {
+		d.rehashStep()
+	}
+
+	tables := 1
+	if d.isRehashing() {
+		tables = 2
+	}
+	maxSizemask := d.mainTable().sizemask
+	if tables > 1 && d.rehashingTable().sizemask > maxSizemask {
+		maxSizemask = d.rehashingTable().sizemask
+	}
+
+	keys := make([]K, 0, count)
+	maxSteps := count * 10
+	index := rand.Uint64() & maxSizemask
+	emptyLength := 0
+	for len(keys) < count && maxSteps > 0 {
+		maxSteps--
+		for j := 0; j < tables; j++ {
+			hashTable := d.hashTables[j]
+			// The buckets of the main table before rehashidx are already migrated
+			if tables == 2 && j == 0 && index < uint64(d.rehashidx) {
+				// If the rehashing table is smaller, jump to the first bucket not yet migrated
+				if index >= uint64(d.rehashingTable().size) {
+					index = uint64(d.rehashidx)
+				} else {
+					continue
+				}
+			}
+			if index >= uint64(hashTable.size) {
+				continue
+			}
+
+			entry := hashTable.table[index]
+			if entry == nil {
+				// Too many consecutive empty buckets, restart from a random position
+				emptyLength++
+				if emptyLength >= 5 && emptyLength > count {
+					index = rand.Uint64() & maxSizemask
+					emptyLength = 0
+				}
+				continue
+			}
+
+			emptyLength = 0
+			for ; entry != nil && len(keys) < count; entry = entry.next {
+				keys = append(keys, entry.key)
+			}
+		}
+		index = (index + 1) & maxSizemask
+	}
+
+	return keys
+}
+
+// FairRandomKey returns a random key like RandomKey, correcting the bias of chain lengths,
+// like Redis dictGetFairRandomKey: a sample of GETFAIR_NUM_ENTRIES keys is taken with
+// SomeKeys and one of them is picked at random.
+//
+// No parameters.
+//
+// Returns:
+// - K: the random key.
+// - bool: false if the dictionary is empty.
+func (d *Dict[K, V]) FairRandomKey() (K, bool) {
+	keys := d.SomeKeys(GETFAIR_NUM_ENTRIES)
+	if len(keys) == 0 {
+		return d.RandomKey()
+	}
+	return keys[rand.IntN(len(keys))], true
+}
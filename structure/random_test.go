@@ -0,0 +1,94 @@
+package structure
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRandomKey(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+
+	_, ok := d.RandomKey()
+	assert.False(t, ok, "Unexpected random key in an empty dictionary")
+
+	d.Set("key1", "value1")
+	key, ok := d.RandomKey()
+	assert.True(t, ok, "Expected a random key")
+	assert.Equal(t, "key1", key, "Unexpected random key")
+
+	for i := 0; i < 100; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	completeRehashing(d)
+	d.expand(1024)
+
+	// Every key can be returned, from both tables while rehashing
+	seen := make(map[string]bool)
+	for i := 0; i < 10000; i++ {
+		key, ok := d.RandomKey()
+		assert.True(t, ok, "Expected a random key")
+		assert.NotEqual(t, "", d.Get(key), "Random key %s should exist", key)
+		seen[key] = true
+	}
+	assert.Len(t, seen, 100, "Expected every key to be returned")
+}
+
+func TestSomeKeys(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	assert.Empty(t, d.SomeKeys(10), "Unexpected keys in an empty dictionary")
+
+	for i := 0; i < 1000; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	completeRehashing(d)
+	d.expand(1 << 12)
+	// Migrate about half of the buckets, so that the sampled buckets of both tables hold keys
+	d.RehashSteps(300)
+	assert.True(t, d.isRehashing(), "Expected the dictionary to be rehashing")
+
+	keys := d.SomeKeys(50)
+	assert.NotEmpty(t, keys, "Expected some keys")
+	assert.True(t, len(keys) <= 50, "SomeKeys should return at most count keys")
+	for _, key := range keys {
+		assert.NotEqual(t, "", d.Get(key), "Sampled key %s should exist", key)
+	}
+
+	small := NewSipHashDict().(*Dict[string, string])
+	small.Set("key1", "value1")
+	small.Set("key2", "value2")
+	assert.True(t, len(small.SomeKeys(10)) <= 2, "SomeKeys should not return more keys than the dictionary size")
+}
+
+func TestSomeKeysWhileShrinking(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	for i := 0; i < 1000; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	completeRehashing(d)
+	for i := 0; i < 950; i++ {
+		d.Delete(fmt.Sprintf("key%d", i))
+	}
+	assert.True(t, d.isRehashing(), "Expected the dictionary to be shrinking")
+
+	for _, key := range d.SomeKeys(20) {
+		assert.NotEqual(t, "", d.Get(key), "Sampled key %s should exist", key)
+	}
+}
+
+func TestFairRandomKey(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+
+	_, ok := d.FairRandomKey()
+	assert.False(t, ok, "Unexpected random key in an empty dictionary")
+
+	for i := 0; i < 100; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	for i := 0; i < 100; i++ {
+		key, ok := d.FairRandomKey()
+		assert.True(t, ok, "Expected a random key")
+		assert.NotEqual(t, "", d.Get(key), "Random key %s should exist", key)
+	}
+}
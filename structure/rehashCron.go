@@ -0,0 +1,114 @@
+package structure
+
+import (
+	"sync"
+	"time"
+)
+
+// IRehasher is implemented by the dictionaries that can be rehashed incrementally
+// for a bounded amount of time.
+type IRehasher interface {
+	RehashMilliseconds(ms int) int
+}
+
+// RehashCron periodically advances the incremental rehashing of a set of dictionaries,
+// like the incremental rehashing performed by Redis databasesCron, so that a dictionary
+// that stops receiving writes does not stay half-migrated forever.
+//
+// Each run rehashes at most one dictionary for the configured budget, starting from the
+// dictionary following the one rehashed by the previous run.
+type RehashCron struct {
+	dicts    []IRehasher
+	interval time.Duration
+	budgetMs int
+	lock     sync.Locker
+	next     int
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewRehashCron creates a new RehashCron.
+//
+// Parameters:
+// - interval: the time between two runs when started with Start.
+// - budgetMs: the time budget in milliseconds of each run.
+// - lock: the lock held around each call to RehashMilliseconds, since a Dict is not
+// safe for concurrent use. It may be nil if the dictionaries synchronize themselves.
+// - dicts: the dictionaries to rehash.
+//
+// Returns:
+// - *RehashCron: a pointer to the newly created RehashCron.
+func NewRehashCron(interval time.Duration, budgetMs int, lock sync.Locker, dicts ...IRehasher) *RehashCron {
+	return &RehashCron{
+		dicts:    dicts,
+		interval: interval,
+		budgetMs: budgetMs,
+		lock:     lock,
+	}
+}
+
+// Run performs a single run of the cron, rehashing the first dictionary that needs it.
+// It can be called directly from an existing event loop instead of using Start.
+//
+// No parameters.
+// Returns the number of rehashing steps performed.
+func (c *RehashCron) Run() int {
+	for i := 0; i < len(c.dicts); i++ {
+		dict := c.dicts[c.next]
+		c.next = (c.next + 1) % len(c.dicts)
+
+		if c.lock != nil {
+			c.lock.Lock()
+		}
+		rehashes := dict.RehashMilliseconds(c.budgetMs)
+		if c.lock != nil {
+			c.lock.Unlock()
+		}
+
+		if rehashes > 0 {
+			return rehashes
+		}
+	}
+	return 0
+}
+
+// Start runs the cron every interval in a background goroutine, until Stop is called.
+// Nothing is done if the cron is already started.
+//
+// No parameters.
+// No return values.
+func (c *RehashCron) Start() {
+	if c.stop != nil {
+		return
+	}
+	c.stop = make(chan struct{})
+	c.done = make(chan struct{})
+
+	go func() {
+		defer close(c.done)
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				c.Run()
+			case <-c.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the background goroutine started by Start and waits for it to return.
+//
+// No parameters.
+// No return values.
+func (c *RehashCron) Stop() {
+	if c.stop == nil {
+		return
+	}
+	close(c.stop)
+	<-c.done
+	c.stop = nil
+}
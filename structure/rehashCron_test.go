@@ -0,0 +1,74 @@
+package structure
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newRehashingDict(n int) *Dict[string, string] {
+	d := NewSipHashDict().(*Dict[string, string])
+	for i := 0; i < n; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	completeRehashing(d)
+	d.expand(int64(n) * 64)
+	return d
+}
+
+func TestRehashCronRun(t *testing.T) {
+	idle := NewSipHashDict().(*Dict[string, string])
+	first := newRehashingDict(1000)
+	second := newRehashingDict(1000)
+
+	cron := NewRehashCron(time.Millisecond, 100, nil, idle, first, second)
+
+	assert.True(t, cron.Run() > 0, "Expected the first run to rehash a dictionary")
+	assert.False(t, first.isRehashing(), "Expected the first dictionary to be rehashed")
+	assert.True(t, second.isRehashing(), "Only one dictionary should be rehashed by each run")
+
+	assert.True(t, cron.Run() > 0, "Expected the second run to rehash a dictionary")
+	assert.False(t, second.isRehashing(), "Expected the second dictionary to be rehashed")
+
+	assert.Equal(t, 0, cron.Run(), "Unexpected rehashing when no dictionary needs it")
+}
+
+func TestRehashCronStartStop(t *testing.T) {
+	var lock sync.Mutex
+	d := newRehashingDict(1000)
+
+	cron := NewRehashCron(time.Millisecond, 1, &lock, d)
+	cron.Start()
+
+	assert.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return !d.isRehashing()
+	}, time.Second, time.Millisecond, "Expected the cron to complete the rehashing")
+
+	cron.Stop()
+	cron.Stop()
+	assert.Len(t, d.GetAllItems(), 1000, "Unexpected number of entries after rehashing")
+}
+
+func TestRehashCronStartTwice(t *testing.T) {
+	var lock sync.Mutex
+	d := newRehashingDict(1000)
+
+	cron := NewRehashCron(time.Millisecond, 1, &lock, d)
+	cron.Start()
+	cron.Start()
+	cron.Stop()
+
+	// No goroutine started by the second call keeps running after Stop
+	lock.Lock()
+	assert.True(t, d.isRehashing(), "Expected the dictionary to be rehashing")
+	lock.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	lock.Lock()
+	defer lock.Unlock()
+	assert.True(t, d.isRehashing(), "Unexpected rehashing after Stop")
+}
@@ -0,0 +1,280 @@
+package structure
+
+import (
+	"math/bits"
+
+	"github.com/dmarro89/go-redis-hashtable/hashing"
+)
+
+// SHARD_MULTIPLIER is 2^64/φ, which mixes every bit of a digest into its top bits.
+const SHARD_MULTIPLIER = uint64(0x9E3779B97F4A7C15)
+
+// ShardedDict is a dictionary safe for concurrent use that spreads its keys over
+// a power of two number of independent shards, so that writers touching different
+// shards do not contend for the same lock.
+//
+// A key is hashed once: the digest is passed down to the shard, which picks the bucket by its
+// low bits, while the shard is chosen by the top bits of the digest multiplied by 2^64/φ
+// (Fibonacci hashing), so that the digests leaving the high bits empty still spread over all
+// the shards. Every shard is a ConcurrentDict with its own lock and its own incremental rehashing.
+type ShardedDict[K comparable, V any] struct {
+	shards    []*ConcurrentDict[K, V]
+	shardBits uint
+	hasher    hashing.IHasher[K]
+}
+
+// NewShardedDict returns a new instance of ShardedDict whose keys are hashed by the given hasher.
+//
+// Parameters:
+// - hasher: the hashing.IHasher used to route each key and compute its bucket.
+// - shards: the number of shards, rounded up to the next power of two.
+//
+// Returns:
+// - IDict: a pointer to the newly created ShardedDict.
+func NewShardedDict[K comparable, V any](hasher hashing.IHasher[K], shards int) IDict[K, V] {
+	shardBits := uint(0)
+	if shards > 1 {
+		shardBits = uint(bits.Len(uint(shards - 1)))
+	}
+
+	d := &ShardedDict[K, V]{
+		shards:    make([]*ConcurrentDict[K, V], 1<<shardBits),
+		shardBits: shardBits,
+		hasher:    hasher,
+	}
+	for i := range d.shards {
+		d.shards[i] = NewConcurrentDict[K, V](hasher).(*ConcurrentDict[K, V])
+	}
+	return d
+}
+
+// NewShardedSipHashDict returns a new instance of a string/string ShardedDict hashed with SipHash-2-4.
+//
+// Parameters:
+// - shards: the number of shards, rounded up to the next power of two.
+//
+// Returns:
+// - IDict: a pointer to the newly created ShardedDict.
+func NewShardedSipHashDict(shards int) IDict[string, string] {
+	return NewShardedDict[string, string](hashing.NewSip24Hasher(), shards)
+}
+
+// shard returns the shard owning the keys with the given digest.
+//
+// Parameters:
+// - hash: the digest of the key to route.
+//
+// Returns:
+// - *ConcurrentDict: the shard owning the key.
+func (s *ShardedDict[K, V]) shard(hash uint64) *ConcurrentDict[K, V] {
+	if s.shardBits == 0 {
+		return s.shards[0]
+	}
+	return s.shards[(hash*SHARD_MULTIPLIER)>>(64-s.shardBits)]
+}
+
+// Get returns the value associated with the given key in the dictionary.
+//
+// Parameters:
+// - key: the key to look up in the dictionary.
+//
+// Return:
+// - V: the value associated with the key, or the zero value of V if the key is not found.
+func (s *ShardedDict[K, V]) Get(key K) V {
+	value, _ := s.Lookup(key)
+	return value
+}
+
+// Lookup returns the value associated with the given key and whether it was found.
+//
+// Parameters:
+// - key: the key to look up in the dictionary.
+//
+// Return:
+// - V: the value associated with the key, or the zero value of V if the key is not found.
+// - bool: true if the key is in the dictionary, false otherwise.
+func (s *ShardedDict[K, V]) Lookup(key K) (V, bool) {
+	hash := s.hasher.Digest(key)
+	return s.shard(hash).lookupHash(hash, key)
+}
+
+// Set sets the value of a key in the dictionary.
+//
+// Parameters:
+//   - key: the key to set the value for.
+//   - value: the value to set.
+//
+// Returns:
+//   - error: always nil, since an existing key is updated.
+func (s *ShardedDict[K, V]) Set(key K, value V) error {
+	hash := s.hasher.Digest(key)
+	return s.shard(hash).setHash(hash, key, value)
+}
+
+// Delete deletes an entry from the dictionary.
+//
+// Parameters:
+// - key: the key of the entry to be deleted.
+//
+// Returns:
+// - error: ErrKeyNotFound if the entry is not found.
+func (s *ShardedDict[K, V]) Delete(key K) error {
+	hash := s.hasher.Digest(key)
+	return s.shard(hash).deleteHash(hash, key)
+}
+
+// SetIfAbsent sets the value of a key only if the key is not in the dictionary, see Dict.SetIfAbsent.
+//
+// Parameters:
+// - key: the key to set the value for.
+// - value: the value to set.
+//
+// Returns:
+// - bool: true if the key was added, false if it was already in the dictionary.
+func (s *ShardedDict[K, V]) SetIfAbsent(key K, value V) bool {
+	hash := s.hasher.Digest(key)
+	return s.shard(hash).setIfAbsentHash(hash, key, value)
+}
+
+// SetIfPresent sets the value of a key only if the key is already in the dictionary, see Dict.SetIfPresent.
+//
+// Parameters:
+// - key: the key to set the value for.
+// - value: the value to set.
+//
+// Returns:
+// - bool: true if the value was updated, false if the key is not in the dictionary.
+func (s *ShardedDict[K, V]) SetIfPresent(key K, value V) bool {
+	hash := s.hasher.Digest(key)
+	return s.shard(hash).setIfPresentHash(hash, key, value)
+}
+
+// GetAndSet sets the value of a key and returns the value it replaced, see Dict.GetAndSet.
+//
+// Parameters:
+// - key: the key to set the value for.
+// - value: the value to set.
+//
+// Returns:
+// - V: the previous value of the key, or the zero value of V if the key was not in the dictionary.
+// - bool: true if the key was already in the dictionary, false if it was added.
+func (s *ShardedDict[K, V]) GetAndSet(key K, value V) (V, bool) {
+	hash := s.hasher.Digest(key)
+	return s.shard(hash).getAndSetHash(hash, key, value)
+}
+
+// GetAndDelete deletes a key and returns the value it held, see Dict.GetAndDelete.
+//
+// Parameters:
+// - key: the key to delete.
+//
+// Returns:
+// - V: the value of the deleted key, or the zero value of V if the key was not in the dictionary.
+// - bool: true if the key was deleted, false if it was not in the dictionary.
+func (s *ShardedDict[K, V]) GetAndDelete(key K) (V, bool) {
+	hash := s.hasher.Digest(key)
+	return s.shard(hash).getAndDeleteHash(hash, key)
+}
+
+// CompareAndSwap sets the value of a key to new only if its current value is equal to old,
+// see Dict.CompareAndSwap.
+//
+// Parameters:
+// - key: the key to update.
+// - old: the value the key is expected to hold.
+// - new: the value to set.
+//
+// Returns:
+// - bool: true if the value was swapped, false if the key is missing or holds another value.
+func (s *ShardedDict[K, V]) CompareAndSwap(key K, old, new V) bool {
+	hash := s.hasher.Digest(key)
+	return s.shard(hash).compareAndSwapHash(hash, key, old, new)
+}
+
+// Upsert sets the value of a key to the result of fn, called with the current value of the key,
+// see Dict.Upsert. fn is called while holding the write lock of the shard, so it must not access the dictionary.
+//
+// Parameters:
+// - key: the key to update or insert.
+// - fn: the function computing the new value from the current one.
+//
+// Returns:
+// - V: the new value of the key.
+func (s *ShardedDict[K, V]) Upsert(key K, fn func(old V, exists bool) V) V {
+	hash := s.hasher.Digest(key)
+	return s.shard(hash).upsertHash(hash, key, fn)
+}
+
+// GetAllItems retrieves all the key-value pairs of the dictionary.
+// Each shard is copied under its own lock, so the result is not a
+// consistent view of the whole dictionary if writers are running.
+//
+// No parameters.
+// Returns a map holding a copy of all the key-value pairs.
+func (s *ShardedDict[K, V]) GetAllItems() map[K]V {
+	items := make(map[K]V)
+	for _, shard := range s.shards {
+		for key, value := range shard.GetAllItems() {
+			items[key] = value
+		}
+	}
+	return items
+}
+
+// Scan iterates incrementally over the elements of the dictionary, one shard after the other.
+// The low shardBits bits of the cursor hold the index of the shard being scanned, and the
+// remaining bits the cursor of the scan of that shard, like the Redis kvstoreScan.
+// Empty shards are skipped.
+// The guarantees of Dict.Scan hold for each shard.
+//
+// Parameters:
+// - cursor: the cursor returned by the previous call, or 0 to start a new iteration.
+// - fn: the function called for each element of the visited buckets.
+//
+// Returns:
+// - uint64: the cursor for the next call, or 0 if the iteration is complete.
+func (s *ShardedDict[K, V]) Scan(cursor uint64, fn func(key K, value V)) uint64 {
+	index := cursor & (uint64(len(s.shards)) - 1)
+	shardCursor := cursor >> s.shardBits
+
+	shardCursor = s.shards[index].Scan(shardCursor, fn)
+	if shardCursor == 0 {
+		// Move to the next shard, skipping the empty ones
+		index++
+		for index < uint64(len(s.shards)) && s.shards[index].Len() == 0 {
+			index++
+		}
+		if index == uint64(len(s.shards)) {
+			return 0
+		}
+	}
+	return shardCursor<<s.shardBits | index
+}
+
+// Len returns the number of entries in the dictionary, summing the lengths of all the shards.
+//
+// No parameters.
+// Returns the number of entries.
+func (s *ShardedDict[K, V]) Len() int {
+	length := 0
+	for _, shard := range s.shards {
+		length += shard.Len()
+	}
+	return length
+}
+
+// RehashMilliseconds performs incremental rehashing on each shard, for at most
+// the given amount of milliseconds per shard.
+//
+// Parameters:
+// - ms: the time budget in milliseconds of each shard.
+//
+// Returns:
+// - int: the number of rehashing steps performed.
+func (s *ShardedDict[K, V]) RehashMilliseconds(ms int) int {
+	rehashes := 0
+	for _, shard := range s.shards {
+		rehashes += shard.RehashMilliseconds(ms)
+	}
+	return rehashes
+}
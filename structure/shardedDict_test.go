@@ -0,0 +1,134 @@
+package structure
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/dmarro89/go-redis-hashtable/hashing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewShardedDict(t *testing.T) {
+	for _, tt := range []struct{ shards, expected int }{{0, 1}, {1, 1}, {2, 2}, {3, 4}, {16, 16}, {17, 32}} {
+		d := NewShardedSipHashDict(tt.shards).(*ShardedDict[string, string])
+		assert.Len(t, d.shards, tt.expected, "Unexpected number of shards for %d", tt.shards)
+	}
+}
+
+func TestShardedDictRouting(t *testing.T) {
+	// Multiplying by an odd constant keeps the digests that only set the top bits in distinct shards
+	hasher := hashing.HasherFunc[uint64](func(key uint64) uint64 { return key << 62 })
+	d := NewShardedDict[uint64, string](hasher, 4).(*ShardedDict[uint64, string])
+
+	for key := uint64(0); key < 4; key++ {
+		d.Set(key, fmt.Sprintf("value%d", key))
+		assert.Equal(t, 1, d.shards[key].Len(), "Key %d should be stored in shard %d", key, key)
+	}
+}
+
+func TestShardedDictSpread(t *testing.T) {
+	fnvHasher := hashing.NewFnv1aHasher()
+	hashers := map[string]hashing.IHasher[string]{
+		"maphash": hashing.NewMapHasher(),
+		"fnv1a":   fnvHasher,
+		// A 32-bit digest leaves the high bits empty
+		"32-bit": hashing.HasherFunc[string](func(key string) uint64 { return uint64(uint32(fnvHasher.Digest(key))) }),
+	}
+
+	for name, hasher := range hashers {
+		t.Run(name, func(t *testing.T) {
+			d := NewShardedDict[string, string](hasher, 8).(*ShardedDict[string, string])
+			for i := 0; i < 8000; i++ {
+				d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+			}
+			for i, shard := range d.shards {
+				assert.InDelta(t, 1000, shard.Len(), 200, "Unexpected number of keys in shard %d", i)
+			}
+		})
+	}
+}
+
+func TestShardedDictHashesOnce(t *testing.T) {
+	if DICT_DEBUG {
+		t.Skip("the validation hashes every entry again")
+	}
+	digests := 0
+	hasher := hashing.HasherFunc[int](func(key int) uint64 { digests++; return uint64(key) })
+	d := NewShardedDict[int, int](hasher, 4)
+	d.Set(1, 1)
+
+	// Two keys fit the initial table of their shard, so no rehashing step hashes the entries again
+	for _, tt := range []struct {
+		name      string
+		operation func()
+	}{
+		{"Set", func() { d.Set(2, 2) }},
+		{"Lookup", func() { d.Lookup(2) }},
+		{"SetIfAbsent", func() { d.SetIfAbsent(2, 3) }},
+		{"SetIfPresent", func() { d.SetIfPresent(2, 3) }},
+		{"GetAndSet", func() { d.GetAndSet(2, 4) }},
+		{"CompareAndSwap", func() { d.CompareAndSwap(2, 4, 5) }},
+		{"Upsert", func() { d.Upsert(2, func(old int, exists bool) int { return old + 1 }) }},
+		{"GetAndDelete", func() { d.GetAndDelete(2) }},
+		{"Delete", func() { d.Delete(1) }},
+	} {
+		digests = 0
+		tt.operation()
+		assert.Equal(t, 1, digests, "%s should hash the key once", tt.name)
+	}
+}
+
+func TestShardedDict(t *testing.T) {
+	d := NewShardedSipHashDict(8)
+
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i)))
+	}
+	assert.Equal(t, 1000, d.Len(), "Unexpected aggregate length")
+
+	for i := 0; i < 1000; i++ {
+		assert.Equal(t, fmt.Sprintf("value%d", i), d.Get(fmt.Sprintf("key%d", i)), "Unexpected value for key%d", i)
+	}
+
+	for i := 0; i < 500; i++ {
+		assert.NoError(t, d.Delete(fmt.Sprintf("key%d", i)))
+	}
+	assert.ErrorIs(t, d.Delete("key0"), ErrKeyNotFound)
+	assert.Equal(t, 500, d.Len(), "Unexpected aggregate length after delete")
+	assert.Len(t, d.GetAllItems(), 500, "Unexpected number of items after delete")
+}
+
+func TestShardedDictScan(t *testing.T) {
+	d := NewShardedSipHashDict(4)
+	assert.Equal(t, uint64(0), d.Scan(0, func(key, value string) {}), "Scan of an empty dictionary should complete")
+
+	for i := 0; i < 1000; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+
+	items := make(map[string]string)
+	cursor := uint64(0)
+	for {
+		cursor = d.Scan(cursor, func(key, value string) { items[key] = value })
+		if cursor == 0 {
+			break
+		}
+	}
+	assert.Equal(t, d.GetAllItems(), items, "Scan should return every element of every shard")
+}
+
+func TestShardedDictRehashMilliseconds(t *testing.T) {
+	d := NewShardedSipHashDict(4).(*ShardedDict[string, string])
+	for i := 0; i < 10000; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	for _, shard := range d.shards {
+		completeRehashing(shard.dict)
+		shard.dict.expand(1 << 14)
+	}
+
+	assert.True(t, d.RehashMilliseconds(1000) > 0, "Expected some rehashing steps")
+	for _, shard := range d.shards {
+		assert.False(t, shard.dict.isRehashing(), "Expected every shard to be rehashed")
+	}
+}
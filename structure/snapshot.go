@@ -0,0 +1,213 @@
+package structure
+
+// Snapshot is a read-only, point-in-time view of a Dict.
+//
+// Taking a snapshot is O(1): the snapshot keeps a copy of the two HashTable headers
+// and shares their bucket arrays and entries with the live Dict, which keeps accepting
+// writes. The first write of the live Dict to a shared bucket array copies the array,
+// and every write to a chain holding entries created before the snapshot copies the
+// chain first (copy-on-write), so the snapshot never sees the changes.
+//
+// A snapshot must be released with Release once it is not needed anymore: while a
+// snapshot is live, the keys and values it shares are not released by the destructors
+// of the DictType, and the writes keep copying the chains holding them.
+type Snapshot[K comparable, V any] struct {
+	dict *Dict[K, V]
+	// live is the Dict the snapshot was taken from, nil once the snapshot is released
+	live *Dict[K, V]
+}
+
+// Snapshot returns a read-only view of the dictionary frozen at this moment.
+//
+// No parameters.
+// Returns a pointer to Snapshot.
+func (d *Dict[K, V]) Snapshot() *Snapshot[K, V] {
+	d.version++
+	d.snapshots++
+	main, rehashing := *d.mainTable(), *d.rehashingTable()
+	d.mainTable().shared = true
+	d.rehashingTable().shared = true
+
+	return &Snapshot[K, V]{
+		dict: &Dict[K, V]{
+			hashTables: [2]*HashTable[K, V]{&main, &rehashing},
+			rehashidx:  d.rehashidx,
+			dictType:   d.dictType,
+			hasher:     d.hasher,
+			version:    d.version,
+		},
+		live: d,
+	}
+}
+
+// Release releases the snapshot, which must not be used anymore. Once every snapshot
+// of a Dict is released, its entries are not shared anymore: the destructors of the
+// DictType release them again and the writes stop copying their chains.
+// Like the writes, Release must not run concurrently with the other operations of the Dict.
+// Releasing a snapshot twice has no effect.
+//
+// No parameters.
+// No return values.
+func (s *Snapshot[K, V]) Release() {
+	if s.live == nil {
+		return
+	}
+
+	d := s.live
+	s.live = nil
+	d.snapshots--
+	if d.snapshots == 0 {
+		d.mainTable().shared = false
+		d.rehashingTable().shared = false
+	}
+}
+
+// Get returns the value associated with the given key when the snapshot was taken.
+//
+// Parameters:
+// - key: the key to look up in the snapshot.
+//
+// Return:
+// - V: the value associated with the key, or the zero value of V if the key is not found.
+func (s *Snapshot[K, V]) Get(key K) V {
+	return s.dict.Get(key)
+}
+
+// Lookup returns the value associated with the given key when the snapshot was taken and whether it was found.
+//
+// Parameters:
+// - key: the key to look up in the snapshot.
+//
+// Return:
+// - V: the value associated with the key, or the zero value of V if the key is not found.
+// - bool: true if the key was in the dictionary, false otherwise.
+func (s *Snapshot[K, V]) Lookup(key K) (V, bool) {
+	return s.dict.Lookup(key)
+}
+
+// Scan iterates incrementally over the elements of the snapshot, see Dict.Scan.
+//
+// Parameters:
+// - cursor: the cursor returned by the previous call, or 0 to start a new iteration.
+// - fn: the function called for each element of the visited buckets.
+//
+// Returns:
+// - uint64: the cursor for the next call, or 0 if the iteration is complete.
+func (s *Snapshot[K, V]) Scan(cursor uint64, fn func(key K, value V)) uint64 {
+	return s.dict.Scan(cursor, fn)
+}
+
+// Len returns the number of entries in the snapshot.
+//
+// No parameters.
+// Returns the number of entries.
+func (s *Snapshot[K, V]) Len() int {
+	return s.dict.Len()
+}
+
+// newEntry creates a new DictEntry tagged with the current version of the Dict.
+//
+// Parameters:
+// - key: the key of the entry.
+// - value: the value of the entry.
+//
+// Returns:
+// - *DictEntry: a pointer to the newly created DictEntry.
+func (d *Dict[K, V]) newEntry(key K, value V) *DictEntry[K, V] {
+	entry := NewDictEntry[K, V](key, value)
+	entry.version = d.version
+	return entry
+}
+
+// isShared checks if the entry was created before the last snapshot while a snapshot is live,
+// and so may be shared with it.
+func (d *Dict[K, V]) isShared(entry *DictEntry[K, V]) bool {
+	return d.snapshots > 0 && entry.version < d.version
+}
+
+// isKeyShared checks if the key of the entry may be returned by a snapshot, so it must not be destroyed.
+func (d *Dict[K, V]) isKeyShared(entry *DictEntry[K, V]) bool {
+	return d.snapshots > 0 && (entry.sharedKey || entry.version < d.version)
+}
+
+// isValueShared checks if the value of the entry may be returned by a snapshot, so it must not be destroyed.
+func (d *Dict[K, V]) isValueShared(entry *DictEntry[K, V]) bool {
+	return d.snapshots > 0 && (entry.sharedValue || entry.version < d.version)
+}
+
+// writableTable copies the bucket array of the hash table if it is shared with a snapshot.
+//
+// Parameters:
+// - hashTable: the hash table about to be modified.
+//
+// No return values.
+func (d *Dict[K, V]) writableTable(hashTable *HashTable[K, V]) {
+	if !hashTable.shared {
+		return
+	}
+	hashTable.table = append(make([]*DictEntry[K, V], 0, len(hashTable.table)), hashTable.table...)
+	hashTable.shared = false
+}
+
+// writableBucket makes a bucket safe to modify in place: the bucket array is copied if it is
+// shared with a snapshot, and the chain is copied if it holds entries shared with a snapshot.
+//
+// Parameters:
+// - hashTable: the hash table of the bucket.
+// - index: the index of the bucket.
+//
+// No return values.
+func (d *Dict[K, V]) writableBucket(hashTable *HashTable[K, V], index uint64) {
+	if d.snapshots == 0 {
+		return
+	}
+	d.writableTable(hashTable)
+
+	shared := false
+	for entry := hashTable.table[index]; entry != nil && !shared; entry = entry.next {
+		shared = d.isShared(entry)
+	}
+	if !shared {
+		return
+	}
+
+	var head *DictEntry[K, V]
+	tail := &head
+	for entry := hashTable.table[index]; entry != nil; entry = entry.next {
+		*tail = d.newEntry(entry.key, entry.value)
+		(*tail).sharedKey, (*tail).sharedValue = d.isKeyShared(entry), d.isValueShared(entry)
+		tail = &(*tail).next
+	}
+	hashTable.table[index] = head
+}
+
+// writableEntry returns the entry of the given key, after making its bucket safe to modify in place.
+//
+// Parameters:
+// - key: the key to look up.
+//
+// Returns:
+// - *DictEntry: the entry of the key, or nil if not found.
+func (d *Dict[K, V]) writableEntry(key K) *DictEntry[K, V] {
+	hash := d.hasher.Digest(key)
+
+	for i, hashTable := range d.hashTables {
+		if len(hashTable.table) == 0 || (i == 1 && !d.isRehashing()) {
+			continue
+		}
+
+		index := hash & hashTable.sizemask
+		for entry := hashTable.table[index]; entry != nil; entry = entry.next {
+			if d.compareKeys(entry.key, key) {
+				d.writableBucket(hashTable, index)
+				entry = hashTable.table[index]
+				for !d.compareKeys(entry.key, key) {
+					entry = entry.next
+				}
+				return entry
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package structure
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// snapshotItems collects all the elements of a snapshot with Scan.
+func snapshotItems(s *Snapshot[string, string]) map[string]string {
+	items := make(map[string]string)
+	cursor := uint64(0)
+	for {
+		cursor = s.Scan(cursor, func(key, value string) { items[key] = value })
+		if cursor == 0 {
+			return items
+		}
+	}
+}
+
+func TestSnapshot_EmptyDict(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	s := d.Snapshot()
+
+	d.Set("key1", "value1")
+	assert.Equal(t, 0, s.Len(), "Unexpected length of a snapshot of an empty dictionary")
+	assert.Equal(t, "", s.Get("key1"), "Snapshot should not see keys added after it was taken")
+	_, found := s.Lookup("key1")
+	assert.False(t, found, "Snapshot should not find keys added after it was taken")
+	assert.Equal(t, "value1", d.Get("key1"), "Unexpected value for key1")
+}
+
+func TestSnapshot_FrozenView(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	expected := make(map[string]string)
+	for i := 0; i < 100; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+		expected[fmt.Sprintf("key%d", i)] = fmt.Sprintf("value%d", i)
+	}
+
+	s := d.Snapshot()
+
+	// Overwrite, delete and add enough keys to expand and rehash the live dictionary
+	for i := 0; i < 50; i++ {
+		assert.NoError(t, d.Set(fmt.Sprintf("key%d", i), "updated"))
+	}
+	for i := 50; i < 100; i++ {
+		assert.NoError(t, d.Delete(fmt.Sprintf("key%d", i)))
+	}
+	for i := 100; i < 1000; i++ {
+		assert.NoError(t, d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i)))
+	}
+	completeRehashing(d)
+
+	assert.Equal(t, 100, s.Len(), "Unexpected length of the snapshot")
+	assert.Equal(t, expected, snapshotItems(s), "Snapshot should not see the changes of the live dictionary")
+	for key, value := range expected {
+		assert.Equal(t, value, s.Get(key), "Unexpected value for %s in the snapshot", key)
+	}
+
+	assert.Equal(t, 950, d.Len(), "Unexpected length of the live dictionary")
+	for i := 0; i < 50; i++ {
+		assert.Equal(t, "updated", d.Get(fmt.Sprintf("key%d", i)), "Unexpected value for key%d", i)
+	}
+	for i := 50; i < 100; i++ {
+		assert.Equal(t, "", d.Get(fmt.Sprintf("key%d", i)), "Unexpected value for deleted key%d", i)
+	}
+}
+
+func TestSnapshot_DuringRehashing(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	for i := 0; i < 1000; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	completeRehashing(d)
+	d.expand(1 << 12)
+	d.RehashSteps(100)
+	assert.True(t, d.isRehashing(), "Expected the dictionary to be rehashing")
+
+	s := d.Snapshot()
+	before := d.GetAllItems()
+
+	// Complete the rehashing and shrink the live dictionary
+	for i := 0; i < 990; i++ {
+		d.Delete(fmt.Sprintf("key%d", i))
+	}
+	completeRehashing(d)
+
+	assert.Equal(t, before, snapshotItems(s), "Snapshot should not see the rehashing of the live dictionary")
+	assert.Equal(t, 10, d.Len(), "Unexpected length of the live dictionary")
+}
+
+func TestSnapshot_Multiple(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	d.Set("key1", "v1")
+	first := d.Snapshot()
+	d.Set("key1", "v2")
+	second := d.Snapshot()
+	d.Set("key1", "v3")
+	d.Set("key2", "v1")
+
+	assert.Equal(t, "v1", first.Get("key1"), "Unexpected value in the first snapshot")
+	assert.Equal(t, "v2", second.Get("key1"), "Unexpected value in the second snapshot")
+	assert.Equal(t, "v3", d.Get("key1"), "Unexpected value in the live dictionary")
+	assert.Equal(t, 1, second.Len(), "Unexpected length of the second snapshot")
+}
+
+func TestWritableBucket(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	d.Set("key1", "value1")
+
+	// Nothing is copied without snapshots
+	table := d.mainTable().table
+	d.writableBucket(d.mainTable(), 0)
+	assert.Same(t, &table[0], &d.mainTable().table[0], "Unexpected copy of the bucket array")
+
+	d.Snapshot()
+	index := d.hasher.Digest("key1") & d.mainTable().sizemask
+	entry := d.mainTable().table[index]
+	d.writableBucket(d.mainTable(), index)
+	assert.NotSame(t, &table[0], &d.mainTable().table[0], "Shared bucket array should be copied")
+	assert.NotSame(t, entry, d.mainTable().table[index], "Shared chain should be copied")
+	assert.Equal(t, "value1", d.mainTable().table[index].value, "Copied entry should hold the same value")
+	assert.False(t, d.isShared(d.mainTable().table[index]), "Copied entry should not be shared")
+}
+
+func TestSnapshot_Release(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	d.Set("key1", "value1")
+	first, second := d.Snapshot(), d.Snapshot()
+	index := d.hasher.Digest("key1") & d.mainTable().sizemask
+
+	// The entries stay shared until every snapshot is released
+	first.Release()
+	first.Release()
+	assert.True(t, d.isShared(d.mainTable().table[index]), "Entries should stay shared with a live snapshot")
+
+	second.Release()
+	assert.False(t, d.isShared(d.mainTable().table[index]), "Entries should not be shared once every snapshot is released")
+
+	// Writes do not copy the bucket array nor the chains anymore
+	table := d.mainTable().table
+	entry := table[index]
+	d.Set("key1", "updated")
+	assert.Same(t, &table[0], &d.mainTable().table[0], "Unexpected copy of the bucket array")
+	assert.Same(t, entry, d.mainTable().table[index], "Unexpected copy of the chain")
+	assert.Equal(t, "updated", d.Get("key1"), "Unexpected value for key1")
+}
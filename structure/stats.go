@@ -0,0 +1,125 @@
+package structure
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// DICT_STATS_VECTLEN is the number of entries of the chain length histogram:
+	// the chains of DICT_STATS_VECTLEN-1 or more entries are counted in the last one.
+	DICT_STATS_VECTLEN = 50
+)
+
+// TableStats holds the statistics of one of the two hash tables of a Dict.
+type TableStats struct {
+	// Size is the number of buckets of the table.
+	Size int64
+	// Used is the number of entries of the table.
+	Used int64
+	// NonEmptyBuckets is the number of buckets holding at least one entry.
+	NonEmptyBuckets int64
+	// MaxChainLength is the number of entries of the longest chain.
+	MaxChainLength int64
+	// AvgChainLength is the average number of entries of the non-empty buckets.
+	AvgChainLength float64
+	// ChainLengths counts the buckets by number of entries: ChainLengths[i] buckets hold i entries.
+	ChainLengths [DICT_STATS_VECTLEN]int64
+}
+
+// DictStats holds the statistics of a Dict, see Dict.Stats.
+type DictStats struct {
+	// Tables holds the statistics of the main table and of the rehashing table.
+	Tables [2]TableStats
+	// Rehashing is true while the entries are migrated from the main table to the rehashing one.
+	Rehashing bool
+	// RehashIndex is the index of the next bucket of the main table to migrate, or -1.
+	RehashIndex int
+}
+
+// Stats walks both hash tables and returns their statistics, like Redis dictGetStats.
+// It is O(size) and meant for debugging.
+//
+// No parameters.
+// Returns the statistics of the dictionary.
+func (d *Dict[K, V]) Stats() DictStats {
+	stats := DictStats{
+		Rehashing:   d.isRehashing(),
+		RehashIndex: d.rehashidx,
+	}
+
+	for i, hashTable := range d.hashTables {
+		tableStats := &stats.Tables[i]
+		tableStats.Size = hashTable.size
+		tableStats.Used = hashTable.used
+
+		totalChainLength := int64(0)
+		for _, entry := range hashTable.table {
+			chainLength := int64(0)
+			for ; entry != nil; entry = entry.next {
+				chainLength++
+			}
+
+			tableStats.ChainLengths[min(chainLength, DICT_STATS_VECTLEN-1)]++
+			if chainLength == 0 {
+				continue
+			}
+			tableStats.NonEmptyBuckets++
+			tableStats.MaxChainLength = max(tableStats.MaxChainLength, chainLength)
+			totalChainLength += chainLength
+		}
+
+		if tableStats.NonEmptyBuckets > 0 {
+			tableStats.AvgChainLength = float64(totalChainLength) / float64(tableStats.NonEmptyBuckets)
+		}
+	}
+
+	return stats
+}
+
+// String formats the statistics like the Redis DEBUG HTSTATS command.
+// The rehashing table is reported only while rehashing.
+//
+// No parameters.
+// Returns the human-readable statistics.
+func (s DictStats) String() string {
+	if s.Tables[0].Used == 0 && s.Tables[1].Used == 0 {
+		return "No stats available for empty dictionaries\n"
+	}
+
+	var b strings.Builder
+	for i, tableStats := range s.Tables {
+		if i == 1 && !s.Rehashing {
+			break
+		}
+
+		name := "main hash table"
+		if i == 1 {
+			name = "rehashing target"
+		}
+		fmt.Fprintf(&b, "Hash table %d stats (%s):\n", i, name)
+		fmt.Fprintf(&b, " table size: %d\n", tableStats.Size)
+		fmt.Fprintf(&b, " number of elements: %d\n", tableStats.Used)
+		if tableStats.Used == 0 {
+			continue
+		}
+
+		computedAvg := 0.0
+		if tableStats.NonEmptyBuckets > 0 {
+			computedAvg = float64(tableStats.Used) / float64(tableStats.NonEmptyBuckets)
+		}
+		fmt.Fprintf(&b, " different slots: %d\n", tableStats.NonEmptyBuckets)
+		fmt.Fprintf(&b, " max chain length: %d\n", tableStats.MaxChainLength)
+		fmt.Fprintf(&b, " avg chain length (counted): %.02f\n", tableStats.AvgChainLength)
+		fmt.Fprintf(&b, " avg chain length (computed): %.02f\n", computedAvg)
+		fmt.Fprintf(&b, " Chain length distribution:\n")
+		for length, buckets := range tableStats.ChainLengths {
+			if buckets == 0 {
+				continue
+			}
+			fmt.Fprintf(&b, "   %d: %d (%.02f%%)\n", length, buckets, float64(buckets)*100/float64(tableStats.Size))
+		}
+	}
+
+	return b.String()
+}
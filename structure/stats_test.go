@@ -0,0 +1,87 @@
+package structure
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/dmarro89/go-redis-hashtable/hashing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats_EmptyDict(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	stats := d.Stats()
+
+	assert.False(t, stats.Rehashing, "Empty dictionary should not be rehashing")
+	assert.Equal(t, int64(0), stats.Tables[0].Size, "Unexpected size")
+	assert.Equal(t, "No stats available for empty dictionaries\n", stats.String(), "Unexpected report")
+}
+
+func TestStats(t *testing.T) {
+	// Every key lands in the bucket of its value modulo the table size
+	d := NewDict[int, int](hashing.HasherFunc[int](func(key int) uint64 { return uint64(key) })).(*Dict[int, int])
+	for _, key := range []int{0, 4, 8, 1, 5, 2} {
+		d.Set(key, key)
+	}
+	d.Delete(2)
+	d.Set(2, 2)
+	d.Resize()
+	completeRehashing(d)
+
+	stats := d.Stats().Tables[0]
+	assert.Equal(t, int64(8), stats.Size, "Unexpected size")
+	assert.Equal(t, int64(6), stats.Used, "Unexpected number of entries")
+	assert.Equal(t, int64(5), stats.NonEmptyBuckets, "Unexpected number of non-empty buckets")
+	assert.Equal(t, int64(2), stats.MaxChainLength, "Unexpected max chain length")
+	assert.Equal(t, 1.2, stats.AvgChainLength, "Unexpected average chain length")
+	assert.Equal(t, int64(3), stats.ChainLengths[0], "Unexpected number of empty buckets")
+	assert.Equal(t, int64(4), stats.ChainLengths[1], "Unexpected number of chains of 1 entry")
+	assert.Equal(t, int64(1), stats.ChainLengths[2], "Unexpected number of chains of 2 entries")
+
+	expected := `Hash table 0 stats (main hash table):
+ table size: 8
+ number of elements: 6
+ different slots: 5
+ max chain length: 2
+ avg chain length (counted): 1.20
+ avg chain length (computed): 1.20
+ Chain length distribution:
+   0: 3 (37.50%)
+   1: 4 (50.00%)
+   2: 1 (12.50%)
+`
+	assert.Equal(t, expected, d.Stats().String(), "Unexpected report")
+}
+
+func TestStats_Rehashing(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	for i := 0; i < 100; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	completeRehashing(d)
+	d.expand(1024)
+	d.rehash(10)
+
+	stats := d.Stats()
+	assert.True(t, stats.Rehashing, "Dictionary should be rehashing")
+	assert.Equal(t, d.rehashidx, stats.RehashIndex, "Unexpected rehash index")
+	assert.Equal(t, int64(1024), stats.Tables[1].Size, "Unexpected size of the rehashing table")
+	assert.Equal(t, int64(100), stats.Tables[0].Used+stats.Tables[1].Used, "Unexpected number of entries")
+	assert.Contains(t, stats.String(), "Hash table 1 stats (rehashing target):", "The rehashing table should be reported")
+
+	completeRehashing(d)
+	assert.NotContains(t, d.Stats().String(), "Hash table 1", "The rehashing table should not be reported")
+}
+
+func TestStats_LongChains(t *testing.T) {
+	// Every key collides
+	d := NewDict[int, int](hashing.HasherFunc[int](func(key int) uint64 { return 0 })).(*Dict[int, int])
+	d.SetMemoryLimit(1)
+	for i := 0; i < 100; i++ {
+		d.Set(i, i)
+	}
+
+	stats := d.Stats().Tables[0]
+	assert.Equal(t, int64(100), stats.MaxChainLength, "Unexpected max chain length")
+	assert.Equal(t, int64(1), stats.ChainLengths[DICT_STATS_VECTLEN-1], "Long chains should be counted in the last entry")
+}
@@ -0,0 +1,110 @@
+package structure
+
+// UnlinkPosition is the position of an entry found by TwoPhaseUnlinkFind,
+// to be passed to TwoPhaseUnlinkFree.
+type UnlinkPosition[K comparable, V any] struct {
+	// link is the pointer to the entry: the bucket itself or the next field of the previous entry
+	link       **DictEntry[K, V]
+	tableIndex int
+}
+
+// Unlink removes the entry of a key from the dictionary without freeing it, like Redis dictUnlink,
+// so that the caller can use its value before calling FreeUnlinked, hashing the key only once.
+//
+// Parameters:
+// - key: the key to remove.
+//
+// Returns:
+// - *DictEntry: the detached entry, or nil if the key is not in the dictionary.
+func (d *Dict[K, V]) Unlink(key K) *DictEntry[K, V] {
+	entry := d.delete(key)
+	if entry != nil {
+		entry.next = nil
+	}
+	return entry
+}
+
+// FreeUnlinked releases an entry returned by Unlink or TwoPhaseUnlinkFind, like Redis dictFreeUnlinkedEntry:
+// its key and value are released with the destructors of the DictType, unless a snapshot can still
+// return them, then cleared, so they are not kept alive by a reference to the entry.
+//
+// Parameters:
+// - entry: the detached entry, nil is allowed.
+//
+// No return values.
+func (d *Dict[K, V]) FreeUnlinked(entry *DictEntry[K, V]) {
+	if entry == nil {
+		return
+	}
+
+	d.freeEntry(entry)
+
+	var zeroKey K
+	var zeroValue V
+	entry.key, entry.value, entry.next = zeroKey, zeroValue, nil
+}
+
+// TwoPhaseUnlinkFind finds the entry of a key and the position needed to unlink it,
+// like Redis dictTwoPhaseUnlinkFind, for callers that must inspect an entry before removing it.
+// If the key is found, the incremental rehashing is paused until TwoPhaseUnlinkFree is called,
+// so the position stays valid; the dictionary must not be modified in between.
+//
+// Parameters:
+// - key: the key to find.
+//
+// Returns:
+// - *DictEntry: the entry of the key, or nil if the key is not in the dictionary.
+// - UnlinkPosition: the position of the entry, to be passed to TwoPhaseUnlinkFree.
+func (d *Dict[K, V]) TwoPhaseUnlinkFind(key K) (*DictEntry[K, V], UnlinkPosition[K, V]) {
+	if d.mainTable().used == 0 && d.rehashingTable().used == 0 {
+		return nil, UnlinkPosition[K, V]{}
+	}
+
+	hash := d.hasher.Digest(key)
+
+	for i, hashTable := range d.hashTables {
+		if len(hashTable.table) == 0 || (i == 1 && !d.isRehashing()) {
+			continue
+		}
+
+		index := hash & hashTable.sizemask
+		for entry := hashTable.table[index]; entry != nil; entry = entry.next {
+			if !d.compareKeys(entry.key, key) {
+				continue
+			}
+
+			// The link will be written, so the chain must not be shared with a snapshot
+			d.writableBucket(hashTable, index)
+			link := &hashTable.table[index]
+			for !d.compareKeys((*link).key, key) {
+				link = &(*link).next
+			}
+
+			d.pauseRehash++
+			return *link, UnlinkPosition[K, V]{link: link, tableIndex: i}
+		}
+	}
+
+	return nil, UnlinkPosition[K, V]{}
+}
+
+// TwoPhaseUnlinkFree unlinks and frees the entry found by TwoPhaseUnlinkFind, then resumes
+// the incremental rehashing, like Redis dictTwoPhaseUnlinkFree.
+//
+// Parameters:
+// - entry: the entry returned by TwoPhaseUnlinkFind, nil is allowed.
+// - position: the position returned by TwoPhaseUnlinkFind.
+//
+// No return values.
+func (d *Dict[K, V]) TwoPhaseUnlinkFree(entry *DictEntry[K, V], position UnlinkPosition[K, V]) {
+	if entry == nil {
+		return
+	}
+
+	*position.link = entry.next
+	d.hashTables[position.tableIndex].used--
+	d.FreeUnlinked(entry)
+	d.pauseRehash--
+	d.shrinkIfNeeded()
+	d.debugValidate()
+}
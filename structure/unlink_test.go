@@ -0,0 +1,84 @@
+package structure
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnlink(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	d.Set("key1", "value1")
+	d.Set("key2", "value2")
+
+	assert.Nil(t, d.Unlink("missing"), "Unexpected entry for a missing key")
+
+	entry := d.Unlink("key1")
+	assert.NotNil(t, entry, "Existing key should be unlinked")
+	assert.Equal(t, "key1", entry.Key(), "Unexpected key of the unlinked entry")
+	assert.Equal(t, "value1", entry.Value(), "Unexpected value of the unlinked entry")
+	assert.Nil(t, entry.next, "Unlinked entry should be detached from its chain")
+	assert.Equal(t, 1, d.Len(), "Unexpected length after unlink")
+	_, found := d.Lookup("key1")
+	assert.False(t, found, "Unlinked key should not be found")
+
+	d.FreeUnlinked(entry)
+	assert.Equal(t, "", entry.Key(), "Freed entry should not keep its key")
+	assert.Equal(t, "", entry.Value(), "Freed entry should not keep its value")
+	d.FreeUnlinked(nil)
+	assert.Equal(t, "value2", d.Get("key2"), "Unexpected value for key2")
+}
+
+func TestTwoPhaseUnlink(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+
+	entry, position := d.TwoPhaseUnlinkFind("missing")
+	assert.Nil(t, entry, "Unexpected entry in an empty dictionary")
+	d.TwoPhaseUnlinkFree(entry, position)
+	assert.Equal(t, 0, d.pauseRehash, "Rehashing should not be paused for a missing key")
+
+	for i := 0; i < 100; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	d.expand(512)
+	assert.True(t, d.isRehashing(), "Dictionary should be rehashing")
+
+	for i := 0; i < 100; i += 2 {
+		key := fmt.Sprintf("key%d", i)
+		entry, position := d.TwoPhaseUnlinkFind(key)
+		assert.NotNil(t, entry, "%s should be found", key)
+		assert.Equal(t, fmt.Sprintf("value%d", i), entry.Value(), "Unexpected value for %s", key)
+		assert.Equal(t, 1, d.pauseRehash, "Rehashing should be paused between the two phases")
+
+		rehashidx := d.rehashidx
+		d.rehashStep()
+		assert.Equal(t, rehashidx, d.rehashidx, "Rehashing should not advance between the two phases")
+
+		d.TwoPhaseUnlinkFree(entry, position)
+		assert.Equal(t, 0, d.pauseRehash, "Rehashing should be resumed")
+	}
+
+	assert.Equal(t, 50, d.Len(), "Unexpected length")
+	for i := 0; i < 100; i++ {
+		_, found := d.Lookup(fmt.Sprintf("key%d", i))
+		assert.Equal(t, i%2 == 1, found, "Unexpected presence of key%d", i)
+	}
+}
+
+func TestTwoPhaseUnlinkSnapshot(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	for i := 0; i < 10; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	s := d.Snapshot()
+
+	entry, position := d.TwoPhaseUnlinkFind("key3")
+	d.TwoPhaseUnlinkFree(entry, position)
+	d.FreeUnlinked(d.Unlink("key4"))
+
+	assert.Equal(t, 8, d.Len(), "Unexpected length")
+	assert.Equal(t, 10, s.Len(), "Unexpected length of the snapshot")
+	assert.Equal(t, "value3", s.Get("key3"), "Snapshot should still see key3")
+	assert.Equal(t, "value4", s.Get("key4"), "Snapshot should still see key4")
+}
@@ -0,0 +1,108 @@
+package structure
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// Validate checks the structural invariants of the dictionary: the size and the sizemask
+// of each table, that every entry is in the bucket of its digest, that no key appears twice
+// across the two tables, that the used counters match the entries, and that the rehashing
+// index is consistent with the migrated buckets. It is O(n) and meant for tests and debugging,
+// see also the dictdebug build tag.
+//
+// No parameters.
+//
+// Returns:
+// - error: an error wrapping ErrInvalidDict describing the first violation found, or nil.
+func (d *Dict[K, V]) Validate() error {
+	if d.pauseRehash < 0 {
+		return fmt.Errorf(`%w: negative pauseRehash %d`, ErrInvalidDict, d.pauseRehash)
+	}
+
+	for i, hashTable := range d.hashTables {
+		if err := d.validateTable(i, hashTable); err != nil {
+			return err
+		}
+	}
+
+	if !d.isRehashing() {
+		if d.rehashingTable().size != 0 || d.rehashingTable().used != 0 {
+			return fmt.Errorf(`%w: rehashing table of size %d with %d entries while not rehashing`,
+				ErrInvalidDict, d.rehashingTable().size, d.rehashingTable().used)
+		}
+		return nil
+	}
+
+	if d.rehashidx < 0 || int64(d.rehashidx) > d.mainTable().size {
+		return fmt.Errorf(`%w: rehashidx %d out of the main table of size %d`, ErrInvalidDict, d.rehashidx, d.mainTable().size)
+	}
+	if d.rehashingTable().size == 0 {
+		return fmt.Errorf(`%w: rehashing without a rehashing table`, ErrInvalidDict)
+	}
+	for index := 0; index < d.rehashidx; index++ {
+		if d.mainTable().table[index] != nil {
+			return fmt.Errorf(`%w: bucket %d of the main table not empty before rehashidx %d`, ErrInvalidDict, index, d.rehashidx)
+		}
+	}
+
+	return nil
+}
+
+// validateTable checks the invariants of one of the two hash tables, see Validate.
+//
+// Parameters:
+// - tableIndex: the index of the table in hashTables.
+// - hashTable: the table to check.
+//
+// Returns:
+// - error: an error wrapping ErrInvalidDict describing the first violation found, or nil.
+func (d *Dict[K, V]) validateTable(tableIndex int, hashTable *HashTable[K, V]) error {
+	if hashTable.size != int64(len(hashTable.table)) {
+		return fmt.Errorf(`%w: table %d has size %d but %d buckets`, ErrInvalidDict, tableIndex, hashTable.size, len(hashTable.table))
+	}
+	if hashTable.size == 0 {
+		if hashTable.used != 0 {
+			return fmt.Errorf(`%w: table %d is empty but has used %d`, ErrInvalidDict, tableIndex, hashTable.used)
+		}
+		return nil
+	}
+	if bits.OnesCount64(uint64(hashTable.size)) != 1 || hashTable.sizemask != uint64(hashTable.size-1) {
+		return fmt.Errorf(`%w: table %d has size %d and sizemask %d`, ErrInvalidDict, tableIndex, hashTable.size, hashTable.sizemask)
+	}
+
+	count := int64(0)
+	for index, entry := range hashTable.table {
+		for ; entry != nil; entry = entry.next {
+			count++
+
+			hash := d.hasher.Digest(entry.key)
+			if hash&hashTable.sizemask != uint64(index) {
+				return fmt.Errorf(`%w: key %v of table %d in bucket %d instead of %d`,
+					ErrInvalidDict, entry.key, tableIndex, index, hash&hashTable.sizemask)
+			}
+
+			// Equal keys have the same digest, so a duplicate is in the same chain or,
+			// while rehashing, in the bucket of the key in the rehashing table
+			for other := entry.next; other != nil; other = other.next {
+				if d.compareKeys(entry.key, other.key) {
+					return fmt.Errorf(`%w: key %v twice in table %d`, ErrInvalidDict, entry.key, tableIndex)
+				}
+			}
+			if tableIndex == 0 && d.isRehashing() && d.rehashingTable().size > 0 {
+				rehashingTable := d.rehashingTable()
+				for other := rehashingTable.table[hash&rehashingTable.sizemask]; other != nil; other = other.next {
+					if d.compareKeys(entry.key, other.key) {
+						return fmt.Errorf(`%w: key %v in both tables`, ErrInvalidDict, entry.key)
+					}
+				}
+			}
+		}
+	}
+
+	if count != hashTable.used {
+		return fmt.Errorf(`%w: table %d has used %d but %d entries`, ErrInvalidDict, tableIndex, hashTable.used, count)
+	}
+
+	return nil
+}
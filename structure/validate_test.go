@@ -0,0 +1,97 @@
+package structure
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newValidatedDict returns a dictionary rehashing from 128 to 1024 buckets, halfway through.
+func newValidatedDict(t *testing.T) *Dict[string, string] {
+	d := NewSipHashDict().(*Dict[string, string])
+	for i := 0; i < 100; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+	}
+	completeRehashing(d)
+	d.expand(1024)
+	d.rehash(8)
+	assert.True(t, d.isRehashing(), "Dictionary should be rehashing")
+	assert.NoError(t, d.Validate(), "Unexpected invalid dictionary")
+	return d
+}
+
+// firstEntry returns the first bucket of the table holding an entry.
+func firstEntry(hashTable *HashTable[string, string]) int {
+	for index, entry := range hashTable.table {
+		if entry != nil {
+			return index
+		}
+	}
+	return -1
+}
+
+func TestValidate(t *testing.T) {
+	d := NewSipHashDict().(*Dict[string, string])
+	assert.NoError(t, d.Validate(), "Empty dictionary should be valid")
+
+	for i := 0; i < 1000; i++ {
+		d.Set(fmt.Sprintf("key%d", i), fmt.Sprintf("value%d", i))
+		if i%100 == 0 {
+			assert.NoError(t, d.Validate(), "Dictionary should be valid after %d sets", i+1)
+		}
+	}
+	for i := 0; i < 1000; i += 3 {
+		d.Delete(fmt.Sprintf("key%d", i))
+		if i%100 == 0 {
+			assert.NoError(t, d.Validate(), "Dictionary should be valid after deleting key%d", i)
+		}
+	}
+	completeRehashing(d)
+	assert.NoError(t, d.Validate(), "Dictionary should be valid after rehashing")
+}
+
+func TestValidate_Corrupted(t *testing.T) {
+	corruptions := map[string]func(d *Dict[string, string]){
+		"entry in the wrong bucket": func(d *Dict[string, string]) {
+			table := d.rehashingTable()
+			index := firstEntry(table)
+			entry := table.table[index]
+			table.table[index] = entry.next
+			other := (uint64(index) + 1) & table.sizemask
+			entry.next = table.table[other]
+			table.table[other] = entry
+		},
+		"used drift": func(d *Dict[string, string]) {
+			d.mainTable().used++
+		},
+		"key in both tables": func(d *Dict[string, string]) {
+			entry := d.rehashingTable().table[firstEntry(d.rehashingTable())]
+			index := d.hasher.Digest(entry.key) & d.mainTable().sizemask
+			duplicate := NewDictEntry[string, string](entry.key, entry.value)
+			duplicate.next = d.mainTable().table[index]
+			d.mainTable().table[index] = duplicate
+			d.mainTable().used++
+		},
+		"rehashidx past the table": func(d *Dict[string, string]) {
+			d.rehashidx = int(d.mainTable().size) + 1
+		},
+		"bucket left before rehashidx": func(d *Dict[string, string]) {
+			d.rehashidx = firstEntry(d.mainTable()) + 1
+		},
+		"wrong sizemask": func(d *Dict[string, string]) {
+			d.rehashingTable().sizemask = 511
+		},
+		"rehashing table while not rehashing": func(d *Dict[string, string]) {
+			d.rehashidx = -1
+		},
+	}
+
+	for name, corrupt := range corruptions {
+		t.Run(name, func(t *testing.T) {
+			d := newValidatedDict(t)
+			corrupt(d)
+			assert.ErrorIs(t, d.Validate(), ErrInvalidDict, "Corruption should be detected")
+		})
+	}
+}
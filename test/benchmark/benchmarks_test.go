@@ -3,9 +3,12 @@ package test
 import (
 	"fmt"
 	"math/rand/v2"
+	"sync"
 	"testing"
 
+	"github.com/dmarro89/go-redis-hashtable/hashing"
 	"github.com/dmarro89/go-redis-hashtable/structure"
+	"github.com/stretchr/testify/assert"
 )
 
 type keyValue struct{ Key, Value string }
@@ -46,15 +49,15 @@ func BenchmarkSet(b *testing.B) {
 		for i := 0; i < e; i++ {
 			n *= 10
 		}
-		b.Run(fmt.Sprintf("1e%d", e), func(b *testing.B) { benchmarkSet(b, n) })
+		b.Run(fmt.Sprintf("1e%d", e), func(b *testing.B) { benchmarkSet(b, n, structure.NewSipHashDict) })
 	}
 }
 
-func benchmarkSet(b *testing.B, n int) {
+func benchmarkSet(b *testing.B, n int, newDict func() structure.IDict[string, string]) {
 	array := prepareArray(n)
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		d := structure.NewSipHashDict()
+		d := newDict()
 		for _, value := range array {
 			d.Set(value.Key, value.Value)
 		}
@@ -69,13 +72,13 @@ func BenchmarkGet(b *testing.B) {
 		for i := 0; i < e; i++ {
 			n *= 10
 		}
-		b.Run(fmt.Sprintf("1e%d", e), func(b *testing.B) { benchmarkGet(b, n) })
+		b.Run(fmt.Sprintf("1e%d", e), func(b *testing.B) { benchmarkGet(b, n, structure.NewSipHashDict) })
 	}
 }
 
-func benchmarkGet(b *testing.B, n int) {
+func benchmarkGet(b *testing.B, n int, newDict func() structure.IDict[string, string]) {
 	array := prepareArray(n)
-	d := structure.NewSipHashDict()
+	d := newDict()
 	for _, value := range array {
 		d.Set(value.Key, value.Value)
 	}
@@ -91,6 +94,84 @@ func benchmarkGet(b *testing.B, n int) {
 	b.StopTimer()
 }
 
+var hashers = []struct {
+	name   string
+	hasher hashing.IHasher[string]
+}{
+	{"Sip24", hashing.NewSip24Hasher()},
+	{"Sip13", hashing.NewSip13Hasher()},
+	{"Fnv1a", hashing.NewFnv1aHasher()},
+	{"Xx", hashing.NewXxHasher()},
+	{"Map", hashing.NewMapHasher()},
+}
+
+func BenchmarkHasherSet(b *testing.B) {
+	var n int
+	for _, h := range hashers {
+		newDict := func() structure.IDict[string, string] { return structure.NewDict[string, string](h.hasher) }
+		for _, e := range []int{1, 2, 3} {
+			n = 1
+			for i := 0; i < e; i++ {
+				n *= 10
+			}
+			b.Run(fmt.Sprintf("%s/1e%d", h.name, e), func(b *testing.B) { benchmarkSet(b, n, newDict) })
+		}
+	}
+}
+
+func BenchmarkHasherGet(b *testing.B) {
+	var n int
+	for _, h := range hashers {
+		newDict := func() structure.IDict[string, string] { return structure.NewDict[string, string](h.hasher) }
+		for _, e := range []int{1, 2, 3} {
+			n = 1
+			for i := 0; i < e; i++ {
+				n *= 10
+			}
+			b.Run(fmt.Sprintf("%s/1e%d", h.name, e), func(b *testing.B) { benchmarkGet(b, n, newDict) })
+		}
+	}
+}
+
+// BenchmarkReadModifyWrite increments counters, comparing a Get followed by a Set,
+// which hashes each key twice, with a single-lookup Upsert.
+func BenchmarkReadModifyWrite(b *testing.B) {
+	var n int
+	for _, e := range []int{1, 2, 3} {
+		n = 1
+		for i := 0; i < e; i++ {
+			n *= 10
+		}
+		b.Run(fmt.Sprintf("GetSet/1e%d", e), func(b *testing.B) { benchmarkGetSet(b, n) })
+		b.Run(fmt.Sprintf("Upsert/1e%d", e), func(b *testing.B) { benchmarkUpsert(b, n) })
+	}
+}
+
+func benchmarkGetSet(b *testing.B, n int) {
+	array := prepareArray(n)
+	d := structure.NewDict[string, int](hashing.NewSip24Hasher())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, value := range array {
+			d.Set(value.Key, d.Get(value.Key)+1)
+		}
+	}
+	b.StopTimer()
+}
+
+func benchmarkUpsert(b *testing.B, n int) {
+	array := prepareArray(n)
+	d := structure.NewDict[string, int](hashing.NewSip24Hasher())
+	increment := func(old int, exists bool) int { return old + 1 }
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, value := range array {
+			d.Upsert(value.Key, increment)
+		}
+	}
+	b.StopTimer()
+}
+
 func BenchmarkDelete(b *testing.B) {
 	var n int
 	for _, e := range []int{1, 2, 3} {
@@ -98,13 +179,13 @@ func BenchmarkDelete(b *testing.B) {
 		for i := 0; i < e; i++ {
 			n *= 10
 		}
-		b.Run(fmt.Sprintf("1e%d", e), func(b *testing.B) { benchmarkDelete(b, n) })
+		b.Run(fmt.Sprintf("1e%d", e), func(b *testing.B) { benchmarkDelete(b, n, structure.NewSipHashDict) })
 	}
 }
 
-func benchmarkDelete(b *testing.B, n int) {
+func benchmarkDelete(b *testing.B, n int, newDict func() structure.IDict[string, string]) {
 	array := prepareArray(n)
-	d := structure.NewSipHashDict()
+	d := newDict()
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -121,6 +202,60 @@ func benchmarkDelete(b *testing.B, n int) {
 	}
 }
 
+// BenchmarkBucketLayout compares the chained DictEntry layout of Dict with the cache-line
+// buckets of BucketDict, both hashed with SipHash-2-4, reporting the allocations of each.
+func BenchmarkBucketLayout(b *testing.B) {
+	layouts := []struct {
+		name    string
+		newDict func() structure.IDict[string, string]
+	}{
+		{"Chained", structure.NewSipHashDict},
+		{"Bucket", structure.NewBucketSipHashDict},
+	}
+
+	for _, layout := range layouts {
+		for _, e := range []int{2, 3, 5} {
+			n := 1
+			for i := 0; i < e; i++ {
+				n *= 10
+			}
+			b.Run(fmt.Sprintf("%s/Set/1e%d", layout.name, e), func(b *testing.B) {
+				b.ReportAllocs()
+				benchmarkSet(b, n, layout.newDict)
+			})
+			b.Run(fmt.Sprintf("%s/Get/1e%d", layout.name, e), func(b *testing.B) {
+				b.ReportAllocs()
+				benchmarkGet(b, n, layout.newDict)
+			})
+			b.Run(fmt.Sprintf("%s/Miss/1e%d", layout.name, e), func(b *testing.B) {
+				b.ReportAllocs()
+				benchmarkMiss(b, n, layout.newDict)
+			})
+			b.Run(fmt.Sprintf("%s/Delete/1e%d", layout.name, e), func(b *testing.B) {
+				b.ReportAllocs()
+				benchmarkDelete(b, n, layout.newDict)
+			})
+		}
+	}
+}
+
+func benchmarkMiss(b *testing.B, n int, newDict func() structure.IDict[string, string]) {
+	array := prepareArray(2 * n)
+	d := newDict()
+	for _, value := range array[:n] {
+		d.Set(value.Key, value.Value)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, value := range array[n:] {
+			if _, found := d.Lookup(value.Key); found {
+				b.Fatalf("Unexpected element {%s} in dictionary", value.Key)
+			}
+		}
+	}
+	b.StopTimer()
+}
+
 func BenchmarkGoMapSet(b *testing.B) {
 	var n int
 	for _, e := range []int{1, 2, 3} {
@@ -200,3 +335,165 @@ func benchmarkGoMapDelete(b *testing.B, n int) {
 		}
 	}
 }
+
+func BenchmarkParallelSet(b *testing.B) {
+	array := prepareArray(1000)
+	dicts := []struct {
+		name    string
+		newDict func() structure.IDict[string, string]
+	}{
+		{"Concurrent", structure.NewConcurrentSipHashDict},
+		{"Sharded16", func() structure.IDict[string, string] { return structure.NewShardedSipHashDict(16) }},
+		{"Cow", structure.NewCowSipHashDict},
+	}
+
+	for _, d := range dicts {
+		b.Run(d.name, func(b *testing.B) {
+			dict := d.newDict()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					value := array[i%len(array)]
+					dict.Set(value.Key, value.Value)
+					i++
+				}
+			})
+		})
+	}
+
+	b.Run("SyncMap", func(b *testing.B) {
+		var m sync.Map
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				value := array[i%len(array)]
+				m.Store(value.Key, value.Value)
+				i++
+			}
+		})
+	})
+}
+
+func BenchmarkParallelGet(b *testing.B) {
+	array := prepareArray(1000)
+	dicts := []struct {
+		name    string
+		newDict func() structure.IDict[string, string]
+	}{
+		{"Concurrent", structure.NewConcurrentSipHashDict},
+		{"Sharded16", func() structure.IDict[string, string] { return structure.NewShardedSipHashDict(16) }},
+		{"Cow", structure.NewCowSipHashDict},
+	}
+
+	for _, d := range dicts {
+		b.Run(d.name, func(b *testing.B) {
+			dict := d.newDict()
+			for _, value := range array {
+				dict.Set(value.Key, value.Value)
+			}
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					value := array[i%len(array)]
+					if dict.Get(value.Key) != value.Value {
+						b.Fatalf("Error getting element {%s, %v} from dictionary", value.Key, value.Value)
+					}
+					i++
+				}
+			})
+		})
+	}
+
+	b.Run("SyncMap", func(b *testing.B) {
+		var m sync.Map
+		for _, value := range array {
+			m.Store(value.Key, value.Value)
+		}
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				value := array[i%len(array)]
+				if v, _ := m.Load(value.Key); v != value.Value {
+					b.Fatalf("Error getting element {%s, %v} from dictionary", value.Key, value.Value)
+				}
+				i++
+			}
+		})
+	})
+}
+
+// BenchmarkGetBytes looks up keys held in a byte slice, like keys read from a network buffer,
+// comparing a conversion to string followed by Get with GetBytes.
+func BenchmarkGetBytes(b *testing.B) {
+	var n int
+	for _, e := range []int{1, 2, 3} {
+		n = 1
+		for i := 0; i < e; i++ {
+			n *= 10
+		}
+		b.Run(fmt.Sprintf("String/1e%d", e), func(b *testing.B) { benchmarkGetBytes(b, n, false) })
+		b.Run(fmt.Sprintf("Bytes/1e%d", e), func(b *testing.B) { benchmarkGetBytes(b, n, true) })
+	}
+}
+
+func benchmarkGetBytes(b *testing.B, n int, direct bool) {
+	array := prepareArray(n)
+	keys := make([][]byte, 0, n)
+	d := structure.NewSipHashBytesDict()
+	for _, value := range array {
+		keys = append(keys, []byte(value.Key))
+		d.SetBytes([]byte(value.Key), []byte(value.Value))
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, key := range keys {
+			var val []byte
+			if direct {
+				val = d.GetBytes(key)
+			} else {
+				val = d.Get(string(key))
+			}
+			if val == nil {
+				b.Fatalf("Error getting element {%s} from dictionary", key)
+			}
+		}
+	}
+	b.StopTimer()
+}
+
+// TestLookupsDoNotAllocate checks that looking up a key, found or missing, in a stable or
+// rehashing dictionary, does not allocate.
+func TestLookupsDoNotAllocate(t *testing.T) {
+	array := prepareArray(1000)
+	d := structure.NewSipHashBytesDict().(*structure.BytesDict)
+	for _, value := range array {
+		d.SetBytes([]byte(value.Key), []byte(value.Value))
+	}
+	for d.RehashSteps(100) {
+	}
+	hit, miss := []byte(array[0].Key), []byte(randomString(21))
+
+	check := func(state string) {
+		lookups := map[string]func(){
+			"GetBytes":    func() { d.GetBytes(hit) },
+			"LookupBytes": func() { d.LookupBytes(miss) },
+			"Get":         func() { d.Get(array[0].Key) },
+		}
+		for name, lookup := range lookups {
+			assert.Zero(t, testing.AllocsPerRun(100, lookup), "%s on a %s dictionary should not allocate", name, state)
+		}
+	}
+
+	check("stable")
+	// Deleting most keys starts a shrinking rehash, so that lookups search both tables
+	for _, value := range array[100:] {
+		d.Delete(value.Key)
+	}
+	assert.True(t, d.Stats().Rehashing, "Expected the dictionary to be rehashing")
+	check("rehashing")
+}
@@ -0,0 +1,157 @@
+package test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/dmarro89/go-redis-hashtable/structure"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Run with -race to detect unsynchronized accesses to the dictionary.
+func TestConcurrentReadersAndWriters(t *testing.T) {
+	d := structure.NewConcurrentSipHashDict()
+
+	const writers = 8
+	const readers = 8
+	const keysPerWriter = 5000
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < keysPerWriter; i++ {
+				key := fmt.Sprintf("writer%d-key%d", w, i)
+				assert.NoError(t, d.Set(key, key))
+				// Delete one key out of two, so that tables also shrink while readers run
+				if i%2 == 1 {
+					assert.NoError(t, d.Delete(fmt.Sprintf("writer%d-key%d", w, i-1)))
+				}
+			}
+		}(w)
+	}
+
+	for r := 0; r < readers; r++ {
+		wg.Add(1)
+		go func(r int) {
+			defer wg.Done()
+			for i := 0; i < keysPerWriter; i++ {
+				key := fmt.Sprintf("writer%d-key%d", r%writers, i)
+				if value := d.Get(key); value != "" {
+					assert.Equal(t, key, value)
+				}
+				if i%1000 == 0 {
+					cursor := uint64(0)
+					for {
+						cursor = d.Scan(cursor, func(key, value string) { assert.Equal(t, key, value) })
+						if cursor == 0 {
+							break
+						}
+					}
+				}
+			}
+		}(r)
+	}
+	wg.Wait()
+
+	items := d.GetAllItems()
+	assert.Len(t, items, writers*keysPerWriter/2)
+	for w := 0; w < writers; w++ {
+		for i := 1; i < keysPerWriter; i += 2 {
+			key := fmt.Sprintf("writer%d-key%d", w, i)
+			assert.Equal(t, key, items[key])
+		}
+	}
+}
+
+func TestShardedConcurrentWriters(t *testing.T) {
+	d := structure.NewShardedSipHashDict(16)
+
+	const writers = 16
+	const keysPerWriter = 5000
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < keysPerWriter; i++ {
+				key := fmt.Sprintf("writer%d-key%d", w, i)
+				assert.NoError(t, d.Set(key, key))
+				assert.Equal(t, key, d.Get(key))
+			}
+			for i := 0; i < keysPerWriter; i += 2 {
+				assert.NoError(t, d.Delete(fmt.Sprintf("writer%d-key%d", w, i)))
+			}
+		}(w)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10; i++ {
+			cursor := uint64(0)
+			for {
+				cursor = d.Scan(cursor, func(key, value string) { assert.Equal(t, key, value) })
+				if cursor == 0 {
+					break
+				}
+			}
+			d.Len()
+		}
+	}()
+	wg.Wait()
+
+	assert.Equal(t, writers*keysPerWriter/2, d.Len())
+}
+
+// Readers of a CowDict never take a lock: run with -race to check that the
+// copy-on-write publication of the chains is correctly synchronized.
+func TestCowDictReadersDuringWrites(t *testing.T) {
+	d := structure.NewCowSipHashDict()
+
+	const keys = 20000
+	for i := 0; i < keys/2; i++ {
+		key := fmt.Sprintf("stable%d", i)
+		assert.NoError(t, d.Set(key, key))
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for r := 0; r < 8; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				// The stable keys are present for the whole test, so they must always be found
+				for i := 0; i < keys/2; i += 97 {
+					key := fmt.Sprintf("stable%d", i)
+					assert.Equal(t, key, d.Get(key))
+				}
+			}
+		}()
+	}
+
+	// Writers grow and shrink the tables while the readers run
+	for round := 0; round < 3; round++ {
+		for i := 0; i < keys; i++ {
+			key := fmt.Sprintf("volatile%d", i)
+			assert.NoError(t, d.Set(key, key))
+		}
+		for i := 0; i < keys; i++ {
+			assert.NoError(t, d.Delete(fmt.Sprintf("volatile%d", i)))
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	assert.Equal(t, keys/2, d.Len())
+}
@@ -0,0 +1,28 @@
+package utility
+
+import "unsafe"
+
+// UnsafeBytes returns the bytes of s without copying them.
+// The returned slice must not be modified, since strings are immutable.
+//
+// Parameters:
+// - s: the string to view as bytes.
+//
+// Returns:
+// - []byte: a slice sharing the memory of s.
+func UnsafeBytes(s string) []byte {
+	return unsafe.Slice(unsafe.StringData(s), len(s))
+}
+
+// UnsafeString returns a string viewing the bytes of b without copying them.
+// b must not be modified while the returned string is in use, so the string
+// must not be retained past the call it is passed to.
+//
+// Parameters:
+// - b: the bytes to view as a string.
+//
+// Returns:
+// - string: a string sharing the memory of b.
+func UnsafeString(b []byte) string {
+	return unsafe.String(unsafe.SliceData(b), len(b))
+}
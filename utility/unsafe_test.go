@@ -0,0 +1,21 @@
+package utility
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnsafeBytes(t *testing.T) {
+	assert.Equal(t, []byte("test message"), UnsafeBytes("test message"), "UnsafeBytes should return the bytes of the string")
+	assert.Empty(t, UnsafeBytes(""), "UnsafeBytes of an empty string should be empty")
+}
+
+func TestUnsafeString(t *testing.T) {
+	b := []byte("test message")
+	assert.Equal(t, "test message", UnsafeString(b), "UnsafeString should return the bytes as a string")
+	assert.Equal(t, "", UnsafeString(nil), "UnsafeString of a nil slice should be empty")
+
+	allocs := testing.AllocsPerRun(100, func() { _ = UnsafeString(b) })
+	assert.Zero(t, allocs, "UnsafeString should not allocate")
+}
@@ -6,14 +6,10 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func TestGenerateRandomBytes(t *testing.T) {
-	generateRandomBytes()
+func TestNewRandomBytes(t *testing.T) {
+	first := NewRandomBytes()
+	second := NewRandomBytes()
 
-	assert.NotNil(t, randomBytes)
-	assert.Len(t, randomBytes, 16)
-}
-
-func TestGetRandomBytes(t *testing.T) {
-	resultBytes := GetRandomBytes()
-	assert.NotEmpty(t, resultBytes)
+	assert.NotEmpty(t, first)
+	assert.NotEqual(t, first, second, "NewRandomBytes() should generate new bytes on each call")
 }